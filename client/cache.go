@@ -0,0 +1,51 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// CachePolicy controls how long listings fetched from Metabase are reused.
+type CachePolicy struct {
+	// Disabled makes every listing hit the API.
+	Disabled bool
+	// TTL is how long a listing is reused, forever when zero. Writes made through the client always invalidate it.
+	TTL time.Duration
+}
+
+// cache memoizes a listing fetched from Metabase. It is safe for concurrent use, and concurrent misses only fetch the
+// listing once.
+type cache[T any] struct {
+	mu        sync.Mutex
+	value     T
+	valid     bool
+	fetchedAt time.Time
+}
+
+func (ca *cache[T]) get(policy CachePolicy, fetch func() (T, error)) (T, error) {
+	if policy.Disabled {
+		return fetch()
+	}
+
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	if ca.valid && (policy.TTL == 0 || time.Since(ca.fetchedAt) < policy.TTL) {
+		return ca.value, nil
+	}
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+	ca.value, ca.valid, ca.fetchedAt = value, true, time.Now()
+	return value, nil
+}
+
+// invalidate drops the cached listing, so that the next read fetches it again.
+func (ca *cache[T]) invalidate() {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	var zero T
+	ca.value, ca.valid = zero, false
+}
@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// groupsServer keeps the permission groups created through it, and counts the listings.
+func groupsServer(listings *int32) *httptest.Server {
+	var mu sync.Mutex
+	groups := PermissionGroups{{Id: 1, Name: "All Users"}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/permissions/group", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			atomic.AddInt32(listings, 1)
+			_ = json.NewEncoder(w).Encode(groups)
+		case http.MethodPost:
+			var pg PermissionGroup
+			_ = json.NewDecoder(r.Body).Decode(&pg)
+			pg.Id = len(groups) + 1
+			groups = append(groups, struct {
+				Id          int    `json:"id"`
+				Name        string `json:"name"`
+				MemberCount int    `json:"member_count"`
+			}{Id: pg.Id, Name: pg.Name})
+			_ = json.NewEncoder(w).Encode(pg)
+		}
+	})
+	mux.HandleFunc("/api/permissions/group/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var pg PermissionGroup
+		_ = json.NewDecoder(r.Body).Decode(&pg)
+		for i := range groups {
+			if groups[i].Id == pg.Id {
+				groups[i].Name = pg.Name
+			}
+		}
+		_ = json.NewEncoder(w).Encode(pg)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Created permission group is listed", func(t *testing.T) {
+		var listings int32
+		svr := groupsServer(&listings)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		before, err := c.GetPermissionGroups(context.Background())
+		assert.Nil(t, err)
+		_, err = c.GetPermissionGroups(context.Background())
+		assert.Nil(t, err)
+		_, err = c.CreatePermissionGroup(context.Background(), "Analysts")
+		assert.Nil(t, err)
+		after, err := c.GetPermissionGroups(context.Background())
+		assert.Nil(t, err)
+
+		assert.Len(t, before, 1)
+		assert.Len(t, after, 2)
+		assert.Equal(t, "Analysts", after[1].Name)
+		assert.Equal(t, int32(2), listings)
+	})
+
+	t.Run("Renamed permission group is listed", func(t *testing.T) {
+		var listings int32
+		svr := groupsServer(&listings)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		_, err := c.GetPermissionGroups(context.Background())
+		assert.Nil(t, err)
+		_, err = c.UpdatePermissionGroup(context.Background(), PermissionGroup{Id: 1, Name: "Everyone"})
+		assert.Nil(t, err)
+		after, err := c.GetPermissionGroups(context.Background())
+		assert.Nil(t, err)
+
+		assert.Equal(t, "Everyone", after[0].Name)
+		assert.Equal(t, int32(2), listings)
+	})
+
+	t.Run("Created collection is listed", func(t *testing.T) {
+		var collections Collections
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/collection", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				_ = json.NewEncoder(w).Encode(collections)
+			case http.MethodPost:
+				var col Collection
+				_ = json.NewDecoder(r.Body).Decode(&col)
+				col.Id = len(collections) + 1
+				collections = append(collections, col)
+				_ = json.NewEncoder(w).Encode(col)
+			}
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		before, err := c.GetCollections(context.Background())
+		assert.Nil(t, err)
+		_, err = c.CreateCollection(context.Background(), Collection{Name: "Finance"})
+		assert.Nil(t, err)
+		after, err := c.GetCollections(context.Background())
+		assert.Nil(t, err)
+
+		assert.Len(t, before, 0)
+		assert.Len(t, after, 1)
+	})
+
+	t.Run("Listing expires after the TTL", func(t *testing.T) {
+		var listings int32
+		svr := groupsServer(&listings)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+			Cache:      CachePolicy{TTL: 20 * time.Millisecond},
+		}
+
+		_, _ = c.GetPermissionGroups(context.Background())
+		_, _ = c.GetPermissionGroups(context.Background())
+		assert.Equal(t, int32(1), listings)
+
+		time.Sleep(30 * time.Millisecond)
+		_, _ = c.GetPermissionGroups(context.Background())
+		assert.Equal(t, int32(2), listings)
+	})
+
+	t.Run("Disabled cache always lists", func(t *testing.T) {
+		var listings int32
+		svr := groupsServer(&listings)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+			Cache:      CachePolicy{Disabled: true},
+		}
+
+		_, _ = c.GetPermissionGroups(context.Background())
+		_, _ = c.GetPermissionGroups(context.Background())
+		assert.Equal(t, int32(2), listings)
+	})
+
+	t.Run("Concurrent creates and reads", func(t *testing.T) {
+		var listings int32
+		svr := groupsServer(&listings)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				_, err := c.CreatePermissionGroup(context.Background(), "Group")
+				assert.Nil(t, err)
+			}()
+			go func() {
+				defer wg.Done()
+				_, err := c.GetPermissionGroups(context.Background())
+				assert.Nil(t, err)
+			}()
+		}
+		wg.Wait()
+
+		groups, err := c.GetPermissionGroups(context.Background())
+		assert.Nil(t, err)
+		assert.Len(t, groups, 11)
+	})
+}
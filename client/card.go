@@ -0,0 +1,91 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// Card is a saved question, model or metric.
+type Card struct {
+	Id           int     `json:"id,omitempty"`
+	Name         string  `json:"name"`
+	Description  *string `json:"description"`
+	CollectionId *int    `json:"collection_id"`
+	Display      string  `json:"display"`
+	// DatasetQuery is either a native or an MBQL query, kept as raw JSON.
+	DatasetQuery          json.RawMessage `json:"dataset_query"`
+	VisualizationSettings json.RawMessage `json:"visualization_settings"`
+	// Type is either question, model or metric.
+	Type     string `json:"type,omitempty"`
+	CacheTTL *int   `json:"cache_ttl"`
+	Archived bool   `json:"archived"`
+}
+
+func (c *Client) GetCard(ctx context.Context, id int) (Card, error) {
+	url := fmt.Sprintf("%s/api/card/%d", c.BaseURL, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	card := Card{}
+	if err != nil {
+		return card, err
+	}
+	if err := c.sendRequest(req, &card); err != nil {
+		return card, err
+	}
+
+	log.Printf("[INFO] Got card '%+v'", card)
+	return card, nil
+}
+
+func (c *Client) CreateCard(ctx context.Context, card Card) (Card, error) {
+	url := fmt.Sprintf("%s/api/card", c.BaseURL)
+	b := new(bytes.Buffer)
+	_ = json.NewEncoder(b).Encode(card)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, b)
+	if err != nil {
+		return card, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	created := Card{}
+	if err := c.sendRequest(req, &created); err != nil {
+		return card, err
+	}
+
+	log.Printf("[INFO] Created card '%+v'", created)
+	return created, nil
+}
+
+func (c *Client) UpdateCard(ctx context.Context, card Card) (Card, error) {
+	url := fmt.Sprintf("%s/api/card/%d", c.BaseURL, card.Id)
+	b := new(bytes.Buffer)
+	_ = json.NewEncoder(b).Encode(card)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, b)
+	if err != nil {
+		return card, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	updated := Card{}
+	if err := c.sendRequest(req, &updated); err != nil {
+		return card, err
+	}
+
+	log.Printf("[INFO] Updated card '%+v'", updated)
+	return updated, nil
+}
+
+func (c *Client) DeleteCard(ctx context.Context, id int) error {
+	url := fmt.Sprintf("%s/api/card/%d", c.BaseURL, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	if err := c.sendRequest(req, nil); err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Deleted card with id[%d]", id)
+	return nil
+}
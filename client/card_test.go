@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCard(t *testing.T) {
+	t.Parallel()
+
+	collectionId := 4
+	description := "Revenue by month"
+	cacheTTL := 60
+
+	t.Run("Get card", func(t *testing.T) {
+		expected := Card{
+			Id:                    1,
+			Name:                  "Revenue",
+			Description:           &description,
+			CollectionId:          &collectionId,
+			Display:               "line",
+			DatasetQuery:          json.RawMessage(`{"database":2,"native":{"query":"SELECT 1","template-tags":{}},"type":"native"}`),
+			VisualizationSettings: json.RawMessage(`{}`),
+			Type:                  "question",
+			CacheTTL:              &cacheTTL,
+		}
+		svr := server("/api/card/1", http.MethodGet, expected)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		card, err := c.GetCard(context.Background(), 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, card)
+	})
+
+	t.Run("Create card", func(t *testing.T) {
+		toBeCreated := Card{
+			Name:                  "Revenue",
+			Display:               "table",
+			DatasetQuery:          json.RawMessage(`{"database":2,"type":"native","native":{"query":"SELECT 1"}}`),
+			VisualizationSettings: json.RawMessage(`{}`),
+			Type:                  "model",
+		}
+		var received map[string]interface{}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/card", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&received)
+			created := toBeCreated
+			created.Id = 1
+			_ = json.NewEncoder(w).Encode(created)
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		card, err := c.CreateCard(context.Background(), toBeCreated)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, card.Id)
+		assert.Equal(t, "model", received["type"])
+		assert.Nil(t, received["collection_id"])
+		assert.Equal(t, map[string]interface{}{"query": "SELECT 1"}, received["dataset_query"].(map[string]interface{})["native"])
+	})
+
+	t.Run("Update card", func(t *testing.T) {
+		toBeUpdated := Card{
+			Id:                    1,
+			Name:                  "Renamed",
+			Display:               "table",
+			DatasetQuery:          json.RawMessage(`{}`),
+			VisualizationSettings: json.RawMessage(`{}`),
+		}
+		svr := server("/api/card/1", http.MethodPut, toBeUpdated)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		card, err := c.UpdateCard(context.Background(), toBeUpdated)
+
+		assert.Nil(t, err)
+		assert.Equal(t, toBeUpdated, card)
+	})
+
+	t.Run("Delete card", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/card/1", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodDelete:
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		err := c.DeleteCard(context.Background(), 1)
+
+		assert.Nil(t, err)
+	})
+}
@@ -2,8 +2,8 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -12,17 +12,31 @@ import (
 	"time"
 )
 
-var mu sync.Mutex
-
+// Client is safe for concurrent use, as Terraform calls the resources concurrently. Its configuration must not be
+// changed once in use.
 type Client struct {
 	BaseURL    string
 	HTTPClient *http.Client
+	Retry      RetryPolicy
+	Cache      CachePolicy
+	// GraphBatchWindow is how long collection graph changes are collected before being sent together.
+	GraphBatchWindow time.Duration
+
+	sessionMu    sync.RWMutex
+	sessionId    string
+	loginDetails LoginDetails
+	apiKey       string
+	userAgent    string
+
+	// The graphs are written whole along with their revision, so concurrent updates of the same graph would conflict.
+	collectionGraphMu  sync.Mutex
+	permissionsGraphMu sync.Mutex
+
+	collectionGraphBatchMu sync.Mutex
+	collectionGraphBatch   *collectionGraphBatch
 
-	sessionId        string
-	userAgent        string
-	users            *Users
-	permissionGroups *PermissionGroups
-	collections      *Collections
+	permissionGroups cache[PermissionGroups]
+	collections      cache[Collections]
 }
 
 type LoginDetails struct {
@@ -30,6 +44,7 @@ type LoginDetails struct {
 	Username  string
 	Password  string
 	SessionId string
+	ApiKey    string
 	UserAgent string
 }
 
@@ -46,69 +61,65 @@ type LoginResponse struct {
 	Id string `json:"id"`
 }
 
-type ErrorResponse struct {
-	Errors  map[string]string `json:"errors"`
-	Message string            `json:"message"`
-}
-
-func NewClient(l LoginDetails) (LoginSuccess, error) {
+func NewClient(ctx context.Context, l LoginDetails) (LoginSuccess, error) {
 	log.Printf("[INFO] Creating new client for host '%s'", l.Host)
 	httpClient := &http.Client{
 		Timeout: time.Minute,
 	}
 	var sessionId string
 
+	// API keys are sent with every request, so there is no session to create
+	if l.ApiKey != "" {
+		log.Printf("[DEBUG] Using API key authentication")
+		return LoginSuccess{
+			Client: &Client{
+				BaseURL:    l.Host,
+				apiKey:     l.ApiKey,
+				HTTPClient: httpClient,
+				Retry:      DefaultRetryPolicy,
+				userAgent:  l.UserAgent,
+
+				GraphBatchWindow: DefaultGraphBatchWindow,
+			},
+		}, nil
+	}
+
 	// Re-use existing sessionId if possible
 	// Session Id is valid
-	sessionId = loginWithSessionId(l, httpClient, sessionId)
+	sessionId = loginWithSessionId(ctx, l, httpClient, sessionId)
 
 	if sessionId == "" { // Login with username/password
-		log.Printf("[DEBUG] Logging in with username/password")
-		creds := map[string]string{"username": l.Username, "password": l.Password}
-		b := new(bytes.Buffer)
-		_ = json.NewEncoder(b).Encode(creds)
-
-		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/session", l.Host), b)
-		req.Header.Set("Content-Type", "application/json")
-
-		res, err := httpClient.Do(req)
-		if err != nil {
-			log.Panicf("[ERROR] Error in authentication: %s", err)
+		var err error
+		if sessionId, err = login(ctx, l, httpClient); err != nil {
+			log.Printf("[ERROR] Error in authentication: %s", err)
 			return LoginSuccess{}, err
 		}
-		defer res.Body.Close()
-
-		if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
-			var errRes ErrorResponse
-			if err = json.NewDecoder(res.Body).Decode(&errRes); err == nil {
-				log.Panicf("[ERROR] Error in request[%+v]: Got response[status=%+v, username=%s]", req.URL, res.Status, l.Username)
-			}
-			log.Panicf("[ERROR] unknown error, status code: %d", res.StatusCode)
-		}
-
-		var resp LoginResponse
-		_ = json.NewDecoder(res.Body).Decode(&resp)
-		sessionId = resp.Id
 	}
 
 	return LoginSuccess{
 		Client: &Client{
-			BaseURL:    l.Host,
-			sessionId:  sessionId,
-			HTTPClient: httpClient,
-			userAgent:  l.UserAgent,
+			BaseURL:      l.Host,
+			sessionId:    sessionId,
+			loginDetails: l,
+			HTTPClient:   httpClient,
+			Retry:        DefaultRetryPolicy,
+			userAgent:    l.UserAgent,
+
+			GraphBatchWindow: DefaultGraphBatchWindow,
 		},
 		SessionId: sessionId,
 	}, nil
 }
 
-func loginWithSessionId(l LoginDetails, httpClient *http.Client, sessionId string) string {
+func loginWithSessionId(ctx context.Context, l LoginDetails, httpClient *http.Client, sessionId string) string {
 	if l.SessionId != "" {
 		log.Printf("[DEBUG] Checking if existing sessionId is valid")
-		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/user/current", l.Host), nil)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/user/current", l.Host), nil)
+		req.Header.Set("X-Metabase-Session", l.SessionId)
 		res, err := httpClient.Do(req)
 		if err != nil {
 			log.Printf("[WARN] Error fetching current user: %s", err)
+			return sessionId
 		}
 		defer res.Body.Close()
 
@@ -120,26 +131,126 @@ func loginWithSessionId(l LoginDetails, httpClient *http.Client, sessionId strin
 	return sessionId
 }
 
-func (c *Client) sendRequest(req *http.Request, v interface{}) error {
-	req.Header.Set("X-Metabase-Session", c.sessionId)
+// login creates a new session with the username/password and returns its id.
+func login(ctx context.Context, l LoginDetails, httpClient *http.Client) (string, error) {
+	log.Printf("[DEBUG] Logging in with username/password")
+	creds := map[string]string{"username": l.Username, "password": l.Password}
+	b := new(bytes.Buffer)
+	_ = json.NewEncoder(b).Encode(creds)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/api/session", l.Host), b)
+	if err != nil {
+		return "", &LoginError{Kind: ErrHostUnreachable, Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", l.UserAgent)
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", &LoginError{Kind: ErrHostUnreachable, Err: err}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
+		body, _ := io.ReadAll(res.Body)
+		return "", newLoginError(res.StatusCode, body)
+	}
+
+	var resp LoginResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return "", &LoginError{Kind: ErrLoginFailed, StatusCode: res.StatusCode, Err: err}
+	}
+	if resp.Id == "" {
+		return "", &LoginError{Kind: ErrLoginFailed, StatusCode: res.StatusCode, Message: "response did not contain a session id"}
+	}
+	return resp.Id, nil
+}
+
+// reauthenticate replaces an expired session with a new one. Concurrent callers that saw the same expired
+// session only log in once, the others pick up the session created by the first one.
+func (c *Client) reauthenticate(ctx context.Context, expired string) error {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+
+	if c.sessionId != expired {
+		return nil
+	}
+	log.Printf("[INFO] Session expired, logging in again to '%s'", c.BaseURL)
+	sessionId, err := login(ctx, c.loginDetails, c.HTTPClient)
+	if err != nil {
+		return err
+	}
+	c.sessionId = sessionId
+	return nil
+}
+
+func (c *Client) canReauthenticate() bool {
+	return c.apiKey == "" && c.loginDetails.Username != "" && c.loginDetails.Password != ""
+}
+
+// setAuthHeaders authenticates the request and returns the session id used, if any.
+func (c *Client) setAuthHeaders(req *http.Request) string {
 	req.Header.Set("User-Agent", c.userAgent)
-	res, err := c.HTTPClient.Do(req)
+	if c.apiKey != "" {
+		req.Header.Set("X-API-KEY", c.apiKey)
+		return ""
+	}
+	c.sessionMu.RLock()
+	sessionId := c.sessionId
+	c.sessionMu.RUnlock()
+	req.Header.Set("X-Metabase-Session", sessionId)
+	return sessionId
+}
+
+// replayRequest copies the request with a fresh body so that it can be sent again.
+func replayRequest(req *http.Request) (*http.Request, error) {
+	replay := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("request body of %s %s cannot be replayed", req.Method, req.URL)
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		replay.Body = body
+	}
+	return replay, nil
+}
+
+func (c *Client) sendRequest(req *http.Request, v interface{}) error {
+	sessionId := c.setAuthHeaders(req)
+	res, err := c.do(req)
 	if err != nil {
 		return err
 	}
 
+	// The session expired or was revoked, log in again and replay the request once
+	if res.StatusCode == http.StatusUnauthorized && c.canReauthenticate() {
+		res.Body.Close()
+		if err := c.reauthenticate(req.Context(), sessionId); err != nil {
+			return err
+		}
+		if req, err = replayRequest(req); err != nil {
+			return err
+		}
+		c.setAuthHeaders(req)
+		if res, err = c.do(req); err != nil {
+			return err
+		}
+	}
+
 	defer res.Body.Close()
 
 	// Not successful
 	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
-		var errRes ErrorResponse
 		b, _ := io.ReadAll(res.Body)
-		if err = json.NewDecoder(bytes.NewReader(b)).Decode(&errRes); err == nil {
-			errorMsg := fmt.Sprintf("errors='%+v', message='%s'", errRes.Errors, errRes.Message)
-			log.Printf("[ERROR] Error in request[%+v]: Got response[status='%+v', errors='%s']", req.URL, res.Status, errorMsg)
-			return errors.New(errorMsg)
-		}
-		return fmt.Errorf("status code: %d, error:%s", res.StatusCode, string(b))
+		apiErr := newAPIError(req, res, b)
+		log.Printf("[ERROR] Error in request[%+v]: Got response[status='%+v', errors='%+v', message='%s']", req.URL, res.Status, apiErr.Errors, apiErr.Message)
+		return apiErr
 	}
 
 	// Successful but no body
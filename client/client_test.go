@@ -1,16 +1,23 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-uuid"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestLogin(t *testing.T) {
+	t.Parallel()
+
 	t.Run("1st time login", func(t *testing.T) {
 		sessionId, _ := uuid.GenerateUUID()
 		loginResponse := LoginResponse{Id: sessionId}
@@ -28,7 +35,7 @@ func TestLogin(t *testing.T) {
 			UserAgent: "test",
 		}
 
-		client, err := NewClient(l)
+		client, err := NewClient(context.Background(), l)
 
 		assert.Nil(t, err)
 		assert.Equal(t, sessionId, client.SessionId)
@@ -50,7 +57,7 @@ func TestLogin(t *testing.T) {
 			UserAgent: "test",
 		}
 
-		success, err := NewClient(l)
+		success, err := NewClient(context.Background(), l)
 
 		assert.Nil(t, err)
 		assert.NotNil(t, success.Client)
@@ -77,10 +84,292 @@ func TestLogin(t *testing.T) {
 			UserAgent: "test",
 		}
 
-		success, err := NewClient(l)
+		success, err := NewClient(context.Background(), l)
 
 		assert.Nil(t, err)
 		assert.NotNil(t, success.Client)
 		assert.Equal(t, sessionId, success.SessionId)
 	})
+	t.Run("Login with API key skips session creation", func(t *testing.T) {
+		apiKey := "mb_test_key"
+		var loginCalled bool
+		var receivedKey, receivedSession string
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/session", func(w http.ResponseWriter, r *http.Request) {
+			loginCalled = true
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		mux.HandleFunc("/api/permissions/group", func(w http.ResponseWriter, r *http.Request) {
+			receivedKey = r.Header.Get("X-API-KEY")
+			receivedSession = r.Header.Get("X-Metabase-Session")
+			_ = json.NewEncoder(w).Encode(PermissionGroups{})
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+
+		l := LoginDetails{
+			Host:      svr.URL,
+			ApiKey:    apiKey,
+			UserAgent: "test",
+		}
+
+		success, err := NewClient(context.Background(), l)
+		assert.Nil(t, err)
+		assert.Empty(t, success.SessionId)
+
+		_, err = success.Client.GetPermissionGroups(context.Background())
+
+		assert.Nil(t, err)
+		assert.False(t, loginCalled)
+		assert.Equal(t, apiKey, receivedKey)
+		assert.Empty(t, receivedSession)
+	})
+}
+
+func TestLoginErrors(t *testing.T) {
+	t.Parallel()
+
+	loginServer := func(status int, body string) *httptest.Server {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/session", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		})
+		return httptest.NewServer(mux)
+	}
+	login := func(host string) error {
+		_, err := NewClient(context.Background(), LoginDetails{
+			Host:      host,
+			Username:  "user",
+			Password:  "pass",
+			UserAgent: "test",
+		})
+		return err
+	}
+
+	t.Run("Host unreachable", func(t *testing.T) {
+		svr := httptest.NewServer(http.NewServeMux())
+		svr.Close()
+
+		err := login(svr.URL)
+
+		assert.ErrorIs(t, err, ErrHostUnreachable)
+	})
+
+	t.Run("Bad credentials", func(t *testing.T) {
+		svr := loginServer(http.StatusUnauthorized, `{"errors":{"password":"did not match stored password"}}`)
+		defer svr.Close()
+
+		err := login(svr.URL)
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+		assert.Contains(t, err.Error(), "did not match stored password")
+	})
+
+	t.Run("Rate-limited login", func(t *testing.T) {
+		svr := loginServer(http.StatusBadRequest, `{"errors":{"username":"Too many attempts! You must wait 15 seconds before trying again."}}`)
+		defer svr.Close()
+
+		err := login(svr.URL)
+
+		assert.ErrorIs(t, err, ErrLoginRateLimited)
+	})
+
+	t.Run("Rate-limited by a proxy", func(t *testing.T) {
+		svr := loginServer(http.StatusTooManyRequests, "Too Many Requests")
+		defer svr.Close()
+
+		err := login(svr.URL)
+
+		assert.ErrorIs(t, err, ErrLoginRateLimited)
+	})
+
+	t.Run("SSO-only account", func(t *testing.T) {
+		svr := loginServer(http.StatusUnauthorized, `{"errors":{"password":"Password login is disabled for this instance."}}`)
+		defer svr.Close()
+
+		err := login(svr.URL)
+
+		assert.ErrorIs(t, err, ErrPasswordLoginDisabled)
+	})
+
+	t.Run("Undecodable login response", func(t *testing.T) {
+		svr := loginServer(http.StatusOK, "<html>not json</html>")
+		defer svr.Close()
+
+		err := login(svr.URL)
+
+		var loginErr *LoginError
+		assert.ErrorAs(t, err, &loginErr)
+		assert.ErrorIs(t, err, ErrLoginFailed)
+	})
+
+	t.Run("Unexpected server error", func(t *testing.T) {
+		svr := loginServer(http.StatusInternalServerError, "boom")
+		defer svr.Close()
+
+		err := login(svr.URL)
+
+		assert.ErrorIs(t, err, ErrLoginFailed)
+		assert.Contains(t, err.Error(), "status code: 500")
+	})
+}
+
+func TestReauthentication(t *testing.T) {
+	t.Parallel()
+
+	reauthServer := func(logins *int, received *[]string) *httptest.Server {
+		sessionId := "" // the client's session is no longer known to the server
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/session", func(w http.ResponseWriter, r *http.Request) {
+			*logins++
+			sessionId = fmt.Sprintf("session-%d", *logins)
+			_ = json.NewEncoder(w).Encode(LoginResponse{Id: sessionId})
+		})
+		mux.HandleFunc("/api/permissions/group", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-API-KEY") != "" || r.Header.Get("X-Metabase-Session") != sessionId {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte("Unauthenticated"))
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			*received = append(*received, string(body))
+			_ = json.NewEncoder(w).Encode(PermissionGroup{Id: 3, Name: "Replayed"})
+		})
+		return httptest.NewServer(mux)
+	}
+
+	t.Run("Log in again on 401 and replay the request with its body", func(t *testing.T) {
+		var logins int
+		var received []string
+		svr := reauthServer(&logins, &received)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:      svr.URL,
+			HTTPClient:   &http.Client{},
+			sessionId:    "expired",
+			loginDetails: LoginDetails{Host: svr.URL, Username: "user", Password: "pass"},
+		}
+
+		pg, err := c.CreatePermissionGroup(context.Background(), "Replayed")
+
+		assert.Nil(t, err)
+		assert.Equal(t, PermissionGroup{Id: 3, Name: "Replayed"}, pg)
+		assert.Equal(t, 1, logins)
+		assert.Equal(t, "session-1", c.sessionId)
+		assert.Len(t, received, 1)
+		assert.JSONEq(t, `{"id":0,"name":"Replayed"}`, received[0])
+	})
+
+	t.Run("Concurrent requests with the same expired session log in once", func(t *testing.T) {
+		var logins int
+		var received []string
+		var mu sync.Mutex
+		svr := reauthServer(&logins, &received)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:      svr.URL,
+			HTTPClient:   &http.Client{},
+			sessionId:    "expired",
+			loginDetails: LoginDetails{Host: svr.URL, Username: "user", Password: "pass"},
+		}
+		// Serialise the mock server handlers, the client itself is exercised concurrently.
+		handler := svr.Config.Handler
+		svr.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			handler.ServeHTTP(w, r)
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := c.CreatePermissionGroup(context.Background(), "Replayed")
+				assert.Nil(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 1, logins)
+		assert.Len(t, received, 5)
+	})
+
+	t.Run("API key clients do not try to log in", func(t *testing.T) {
+		var logins int
+		var received []string
+		svr := reauthServer(&logins, &received)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+			apiKey:     "revoked",
+		}
+
+		_, err := c.CreatePermissionGroup(context.Background(), "Replayed")
+
+		assert.NotNil(t, err)
+		assert.Equal(t, 0, logins)
+	})
+}
+
+func TestContextCancellation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Abort in-flight request", func(t *testing.T) {
+		release := make(chan struct{})
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/permissions/group", func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-release:
+			}
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+		defer close(release)
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+			Retry:      DefaultRetryPolicy,
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := c.GetPermissionGroups(ctx)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Abort collection graph update waiting on conflicts", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/collection/graph", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPut {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(CollectionGraph{Revision: 1})
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+			Retry:      RetryPolicy{MaxRetries: 5, WaitMin: time.Hour, WaitMax: time.Hour},
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := c.UpdateCollectionGraph(ctx, CollectionGraph{})
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Minute)
+	})
 }
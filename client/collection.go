@@ -2,28 +2,108 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
 type Collection struct {
-	Id       int    `json:"id"`
-	ParentId int    `json:"parent_id,omitempty"`
-	Name     string `json:"name"`
-	Color    string `json:"color"`
-	Archived bool   `json:"archived"`
+	Id int `json:"id"`
+	// ParentId is null for the collections in the root collection, sending null moves a collection there.
+	ParentId    *int    `json:"parent_id"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	Color       string  `json:"color"`
+	Archived    bool    `json:"archived"`
+	// AuthorityLevel is "official" for the collections marked as Official, null otherwise.
+	AuthorityLevel *string `json:"authority_level"`
+	// Type is "instance-analytics" for the Metabase analytics collection, empty otherwise.
+	Type string `json:"type,omitempty"`
+	// Namespace is "snippets" for the SQL snippet folders, empty for regular collections.
+	Namespace string `json:"namespace,omitempty"`
+	// Location is the path of the ancestors ids, e.g. "/1/5/" for a collection in collection 5.
+	Location string `json:"location,omitempty"`
+	// PersonalOwnerId is the id of the user owning a personal collection.
+	PersonalOwnerId *int `json:"personal_owner_id,omitempty"`
+}
+
+// Parent returns the id of the collection holding the collection, 0 for the root collection.
+func (col Collection) Parent() int {
+	ancestors := strings.Split(strings.Trim(col.Location, "/"), "/")
+	parentId, _ := strconv.Atoi(ancestors[len(ancestors)-1])
+	return parentId
 }
 
 type Collections []Collection
 
-func (c *Client) GetCollections() (Collections, error) {
-	if c.collections != nil {
-		return *c.collections, nil
+// UnmarshalJSON skips the root collection, the only one listed with a non numeric id.
+func (cols *Collections) UnmarshalJSON(b []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(b, &items); err != nil {
+		return err
+	}
+	*cols = Collections{}
+	for _, item := range items {
+		var probe struct {
+			Id interface{} `json:"id"`
+		}
+		if err := json.Unmarshal(item, &probe); err != nil {
+			return err
+		}
+		if _, isNumber := probe.Id.(float64); !isNumber {
+			continue
+		}
+		var col Collection
+		if err := json.Unmarshal(item, &col); err != nil {
+			return err
+		}
+		*cols = append(*cols, col)
+	}
+	return nil
+}
+
+// Descendants returns the ids of the collections nested at any depth in the given collection.
+func (cols Collections) Descendants(id int) []int {
+	segment := fmt.Sprintf("/%d/", id)
+	var ids []int
+	for _, col := range cols {
+		if strings.Contains(col.Location, segment) {
+			ids = append(ids, col.Id)
+		}
+	}
+	return ids
+}
+
+// Path returns the names of the ancestors of the collection and its own, separated by slashes.
+// Ancestors missing from the collections, such as archived ones, are left out.
+func (cols Collections) Path(col Collection) string {
+	names := make(map[string]string)
+	for _, c := range cols {
+		names[strconv.Itoa(c.Id)] = c.Name
 	}
+	var path []string
+	for _, id := range strings.Split(strings.Trim(col.Location, "/"), "/") {
+		if name, found := names[id]; found {
+			path = append(path, name)
+		}
+	}
+	return strings.Join(append(path, col.Name), "/")
+}
+
+func (c *Client) GetCollections(ctx context.Context) (Collections, error) {
+	return c.collections.get(c.Cache, func() (Collections, error) {
+		return c.getCollections(ctx)
+	})
+}
+
+func (c *Client) getCollections(ctx context.Context) (Collections, error) {
 	url := fmt.Sprintf("%s/api/collection", c.BaseURL)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	collections := Collections{}
 	if err != nil {
 		return collections, err
@@ -33,13 +113,28 @@ func (c *Client) GetCollections() (Collections, error) {
 	}
 
 	log.Printf("[DEBUG] Got collections '%+v'", collections)
-	c.collections = &collections
 	return collections, nil
 }
 
-func (c *Client) GetCollection(id string) (Collection, error) {
+// GetArchivedCollections lists the archived collections, which are left out of GetCollections.
+func (c *Client) GetArchivedCollections(ctx context.Context) (Collections, error) {
+	url := fmt.Sprintf("%s/api/collection?archived=true", c.BaseURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	collections := Collections{}
+	if err != nil {
+		return collections, err
+	}
+	if err := c.sendRequest(req, &collections); err != nil {
+		return collections, err
+	}
+
+	log.Printf("[DEBUG] Got archived collections '%+v'", collections)
+	return collections, nil
+}
+
+func (c *Client) GetCollection(ctx context.Context, id string) (Collection, error) {
 	url := fmt.Sprintf("%s/api/collection/%s", c.BaseURL, id)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	collection := Collection{}
 	if err != nil {
 		return collection, err
@@ -52,11 +147,13 @@ func (c *Client) GetCollection(id string) (Collection, error) {
 	return collection, nil
 }
 
-func (c *Client) CreateCollection(col Collection) (Collection, error) {
+func (c *Client) CreateCollection(ctx context.Context, col Collection) (Collection, error) {
+	defer c.collections.invalidate()
+
 	url := fmt.Sprintf("%s/api/collection", c.BaseURL)
 	b := new(bytes.Buffer)
 	_ = json.NewEncoder(b).Encode(col)
-	req, err := http.NewRequest(http.MethodPost, url, b)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, b)
 	req.Header.Set("Content-Type", "application/json")
 	if err != nil {
 		return col, err
@@ -70,11 +167,13 @@ func (c *Client) CreateCollection(col Collection) (Collection, error) {
 	return created, nil
 }
 
-func (c *Client) UpdateCollection(col Collection) (Collection, error) {
+func (c *Client) UpdateCollection(ctx context.Context, col Collection) (Collection, error) {
+	defer c.collections.invalidate()
+
 	url := fmt.Sprintf("%s/api/collection/%v", c.BaseURL, col.Id)
 	b := new(bytes.Buffer)
 	_ = json.NewEncoder(b).Encode(col)
-	req, err := http.NewRequest(http.MethodPut, url, b)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, b)
 	req.Header.Set("Content-Type", "application/json")
 	if err != nil {
 		return col, err
@@ -87,3 +186,73 @@ func (c *Client) UpdateCollection(col Collection) (Collection, error) {
 	log.Printf("[INFO] Updated collection '%+v'", updated)
 	return updated, nil
 }
+
+// DeleteCollection permanently deletes an archived collection, along with its content.
+func (c *Client) DeleteCollection(ctx context.Context, id int) error {
+	defer c.collections.invalidate()
+
+	url := fmt.Sprintf("%s/api/collection/%d", c.BaseURL, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	if err := c.sendRequest(req, nil); err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Deleted collection with id[%d]", id)
+	return nil
+}
+
+// CollectionItem is a card or a dashboard in a collection. Model is card, dataset (a model), metric or dashboard.
+type CollectionItem struct {
+	Id    int    `json:"id"`
+	Model string `json:"model"`
+	Name  string `json:"name"`
+}
+
+// collectionItemModels are the items moved by MoveCollectionItem, sub-collections are left out.
+var collectionItemModels = []string{"card", "dataset", "metric", "dashboard"}
+
+// GetCollectionItems lists the cards and dashboards directly in the collection.
+func (c *Client) GetCollectionItems(ctx context.Context, id int) ([]CollectionItem, error) {
+	params := url.Values{}
+	for _, model := range collectionItemModels {
+		params.Add("models", model)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/collection/%d/items?%s", c.BaseURL, id, params.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+	items := struct {
+		Data []CollectionItem `json:"data"`
+	}{}
+	if err := c.sendRequest(req, &items); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[DEBUG] Got %d items in collection with id[%d]", len(items.Data), id)
+	return items.Data, nil
+}
+
+// MoveCollectionItem moves a card or a dashboard to another collection.
+func (c *Client) MoveCollectionItem(ctx context.Context, item CollectionItem, collectionId int) error {
+	endpoint := "card"
+	if item.Model == "dashboard" {
+		endpoint = "dashboard"
+	}
+	url := fmt.Sprintf("%s/api/%s/%d", c.BaseURL, endpoint, item.Id)
+	b := new(bytes.Buffer)
+	_ = json.NewEncoder(b).Encode(map[string]int{"collection_id": collectionId})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, b)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.sendRequest(req, nil); err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Moved %s with id[%d] to collection with id[%d]", item.Model, item.Id, collectionId)
+	return nil
+}
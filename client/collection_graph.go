@@ -1,13 +1,10 @@
 package client
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
-	"math/rand"
 	"net/http"
-	"strings"
 	"time"
 )
 
@@ -16,9 +13,9 @@ type CollectionGraph struct {
 	Groups   map[string]map[string]string `json:"groups"`
 }
 
-func (c *Client) GetCollectionGraph() (CollectionGraph, error) {
+func (c *Client) GetCollectionGraph(ctx context.Context) (CollectionGraph, error) {
 	url := fmt.Sprintf("%s/api/collection/graph", c.BaseURL)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 
 	collectionGraph := CollectionGraph{}
 	if err != nil {
@@ -32,44 +29,152 @@ func (c *Client) GetCollectionGraph() (CollectionGraph, error) {
 	return collectionGraph, nil
 }
 
-func (c *Client) UpdateCollectionGraph(cg CollectionGraph) (CollectionGraph, error) {
-	mu.Lock() // Lock the client to prevent concurrent updates as the whole graph has to be updated each time.
+// DefaultGraphBatchWindow is how long collection graph changes are collected before being sent together.
+const DefaultGraphBatchWindow = 100 * time.Millisecond
 
-	defer mu.Unlock() // Unlock the client when the function returns
-	url := fmt.Sprintf("%s/api/collection/graph", c.BaseURL)
+// collectionGraphBatch holds the changes of the callers waiting for the same graph update.
+type collectionGraphBatch struct {
+	changes []*collectionGraphChange
+	done    chan struct{}
+}
+
+// collectionGraphChange is the change of one caller of UpdateCollectionGraph, and its outcome.
+type collectionGraphChange struct {
+	ctx     context.Context
+	groups  map[string]map[string]string
+	updated CollectionGraph
+	err     error
+}
+
+// UpdateCollectionGraph updates the permissions of the groups and collections present in the given graph. Changes
+// made concurrently within the client's GraphBatchWindow are sent in a single update. The returned graph holds the
+// permissions of every group on the collections of the given graph.
+func (c *Client) UpdateCollectionGraph(ctx context.Context, cg CollectionGraph) (CollectionGraph, error) {
+	change := &collectionGraphChange{ctx: ctx, groups: cg.Groups}
+
+	c.collectionGraphBatchMu.Lock()
+	batch := c.collectionGraphBatch
+	if batch == nil {
+		batch = &collectionGraphBatch{done: make(chan struct{})}
+		c.collectionGraphBatch = batch
+		go c.flushCollectionGraph(batch)
+	}
+	batch.changes = append(batch.changes, change)
+	c.collectionGraphBatchMu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return cg, ctx.Err()
+	case <-batch.done:
+	}
+	if change.err != nil {
+		return cg, change.err
+	}
 
-	retries := 7 // limits the max backoff to 128 seconds
-	updated := CollectionGraph{}
-	var err_ret error
-  backoff := rand.Float32()
-	for retries >= 0 {
-		currentCG, err := c.GetCollectionGraph()
-		if err != nil {
-			return cg, err
+	collectionIds := map[string]bool{}
+	for _, collections := range cg.Groups {
+		for collectionId := range collections {
+			collectionIds[collectionId] = true
 		}
-		// Update the revision which is incremented +1 by the server everytime the graph is updated
-		cg.Revision = currentCG.Revision
-		b := new(bytes.Buffer)
-		_ = json.NewEncoder(b).Encode(cg)
-		req, err := http.NewRequest(http.MethodPut, url, b)
-		req.Header.Set("Content-Type", "application/json")
-		if err != nil {
-			err_ret = err
-			break
+	}
+	updated := CollectionGraph{Revision: change.updated.Revision, Groups: map[string]map[string]string{}}
+	for groupId, collections := range change.updated.Groups {
+		for collectionId, access := range collections {
+			if !collectionIds[collectionId] {
+				continue
+			}
+			if updated.Groups[groupId] == nil {
+				updated.Groups[groupId] = map[string]string{}
+			}
+			updated.Groups[groupId][collectionId] = access
 		}
-		if err := c.sendRequest(req, &updated); err != nil {
-			if strings.Contains(err.Error(), "collection_revision_pkey") || strings.Contains(err.Error(), "status code: 409") {
-				log.Printf("[ERROR] There were an error with the collection graph, retries left %d.", retries)
-				time.Sleep(time.Duration(backoff) * time.Second)
-        backoff = backoff * 2
-				retries -= 1
-			} else {
-				err_ret = err
-				break
+	}
+	return updated, nil
+}
+
+// flushCollectionGraph sends the batch once its window is over. Batches are sent one at a time, as each update is
+// made against the latest revision.
+func (c *Client) flushCollectionGraph(batch *collectionGraphBatch) {
+	defer close(batch.done)
+
+	time.Sleep(c.GraphBatchWindow)
+
+	c.collectionGraphBatchMu.Lock()
+	c.collectionGraphBatch = nil
+	c.collectionGraphBatchMu.Unlock()
+
+	c.collectionGraphMu.Lock()
+	defer c.collectionGraphMu.Unlock()
+
+	// The changes of the callers which gave up while waiting are dropped
+	var changes []*collectionGraphChange
+	var ctxs []context.Context
+	groups := map[string]map[string]string{}
+	for _, change := range batch.changes {
+		if change.ctx.Err() != nil {
+			continue
+		}
+		changes = append(changes, change)
+		ctxs = append(ctxs, change.ctx)
+		for groupId, collections := range change.groups {
+			if groups[groupId] == nil {
+				groups[groupId] = map[string]string{}
+			}
+			for collectionId, access := range collections {
+				groups[groupId][collectionId] = access
 			}
-		} else {
-			return updated, nil
 		}
 	}
-	return cg, err_ret
+	if len(changes) == 0 {
+		return
+	}
+
+	// The update goes on as long as one of the callers is still waiting for it
+	ctx, cancel := mergeContexts(ctxs)
+	defer cancel()
+
+	log.Printf("[DEBUG] Updating the collection graph of %d groups", len(groups))
+	var updated CollectionGraph
+	err := c.updateCollectionGraph(ctx, groups, &updated)
+
+	// Metabase rejects the whole graph when one of the changes is invalid, send them one by one so that only the
+	// callers of the invalid ones fail
+	if len(changes) > 1 && HasStatusCode(err, http.StatusBadRequest) {
+		log.Printf("[WARN] The collection graph update of %d changes was rejected, sending them one by one: %s", len(changes), err)
+		for _, change := range changes {
+			change.err = c.updateCollectionGraph(change.ctx, change.groups, &change.updated)
+		}
+		return
+	}
+	for _, change := range changes {
+		change.updated, change.err = updated, err
+	}
+}
+
+func (c *Client) updateCollectionGraph(ctx context.Context, groups map[string]map[string]string, updated *CollectionGraph) error {
+	url := fmt.Sprintf("%s/api/collection/graph", c.BaseURL)
+	currentRevision := func(ctx context.Context) (int, error) {
+		currentCG, err := c.GetCollectionGraph(ctx)
+		return currentCG.Revision, err
+	}
+	return c.updateGraph(ctx, url, currentRevision, func(revision int) interface{} {
+		// Update the revision which is incremented +1 by the server everytime the graph is updated
+		return CollectionGraph{Revision: revision, Groups: groups}
+	}, updated)
+}
+
+// mergeContexts returns a context which is cancelled once all the given contexts are.
+func mergeContexts(ctxs []context.Context) (context.Context, context.CancelFunc) {
+	merged, cancel := context.WithCancel(context.Background())
+	go func() {
+		for _, ctx := range ctxs {
+			select {
+			case <-ctx.Done():
+			case <-merged.Done():
+				return
+			}
+		}
+		cancel()
+	}()
+	return merged, cancel
 }
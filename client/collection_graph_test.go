@@ -1,13 +1,23 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestCollectionGraph(t *testing.T) {
+	t.Parallel()
+
 	t.Run("Get collection graph", func(t *testing.T) {
 		expected := CollectionGraph{
 			Revision: 1,
@@ -32,13 +42,15 @@ func TestCollectionGraph(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		pg, _ := c.GetCollectionGraph()
+		pg, _ := c.GetCollectionGraph(context.Background())
 
 		assert.Equal(t, expected, pg)
 	})
 }
 
 func TestUpdateCollectionGraph(t *testing.T) {
+	t.Parallel()
+
 	t.Run("Update collection graph", func(t *testing.T) {
 		updatedExpected := CollectionGraph{
 			Revision: 2,
@@ -63,12 +75,12 @@ func TestUpdateCollectionGraph(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		_, err := c.UpdateCollectionGraph(updatedExpected)
+		_, err := c.UpdateCollectionGraph(context.Background(), updatedExpected)
 		if err != nil {
 			t.Fatalf("Failed to update collection graph: %v", err)
 		}
 
-		actualUpdatedCG, err := c.GetCollectionGraph()
+		actualUpdatedCG, err := c.GetCollectionGraph(context.Background())
 		if err != nil {
 			t.Fatalf("Failed to get collection graph: %v", err)
 		}
@@ -76,3 +88,246 @@ func TestUpdateCollectionGraph(t *testing.T) {
 		assert.Equal(t, updatedExpected, actualUpdatedCG)
 	})
 }
+
+func TestUpdateCollectionGraphConflict(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Retry with the new revision on conflict", func(t *testing.T) {
+		revision := 1
+		puts := 0
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/collection/graph", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				_ = json.NewEncoder(w).Encode(CollectionGraph{Revision: revision})
+			case http.MethodPut:
+				puts++
+				if puts == 1 {
+					// Another client updated the graph in between
+					revision++
+					w.WriteHeader(http.StatusConflict)
+					return
+				}
+				var cg CollectionGraph
+				_ = json.NewDecoder(r.Body).Decode(&cg)
+				assert.Equal(t, revision, cg.Revision)
+				cg.Revision++
+				_ = json.NewEncoder(w).Encode(cg)
+			}
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+			Retry:      RetryPolicy{MaxRetries: 2, WaitMin: time.Millisecond, WaitMax: time.Millisecond},
+		}
+
+		updated, err := c.UpdateCollectionGraph(context.Background(), CollectionGraph{Groups: map[string]map[string]string{"4": {"9": "read"}}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, puts)
+		assert.Equal(t, 3, updated.Revision)
+	})
+}
+
+func TestUpdateCollectionGraphConcurrently(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Concurrent updates are sent together", func(t *testing.T) {
+		var puts int32
+		var mu sync.Mutex
+		cg := CollectionGraph{Revision: 1, Groups: map[string]map[string]string{}}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/collection/graph", func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			if r.Method == http.MethodPut {
+				atomic.AddInt32(&puts, 1)
+				var update CollectionGraph
+				_ = json.NewDecoder(r.Body).Decode(&update)
+				for groupId, collections := range update.Groups {
+					if cg.Groups[groupId] == nil {
+						cg.Groups[groupId] = map[string]string{}
+					}
+					for collectionId, access := range collections {
+						cg.Groups[groupId][collectionId] = access
+					}
+				}
+				cg.Revision++
+			}
+			_ = json.NewEncoder(w).Encode(cg)
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:          svr.URL,
+			HTTPClient:       &http.Client{},
+			GraphBatchWindow: 50 * time.Millisecond,
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				collectionId := strconv.Itoa(i + 10)
+				updated, err := c.UpdateCollectionGraph(context.Background(), CollectionGraph{
+					Groups: map[string]map[string]string{"4": {collectionId: "read"}},
+				})
+				assert.Nil(t, err)
+				// Each caller only gets the permissions of its own collections
+				assert.Equal(t, map[string]map[string]string{"4": {collectionId: "read"}}, updated.Groups)
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), puts)
+		assert.Equal(t, 2, cg.Revision)
+		assert.Len(t, cg.Groups["4"], 10)
+	})
+
+	t.Run("Clients of different hosts don't wait for each other", func(t *testing.T) {
+		other := graphUpdateMockServer()
+		defer other.Close()
+		otherClient := Client{
+			BaseURL:    other.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		// The update of the first host only completes once the other host has been updated
+		otherUpdated := make(chan struct{})
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/collection/graph", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPut {
+				select {
+				case <-otherUpdated:
+				case <-time.After(5 * time.Second):
+					w.WriteHeader(http.StatusGatewayTimeout)
+					return
+				}
+			}
+			_ = json.NewEncoder(w).Encode(CollectionGraph{Revision: 1})
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		done := make(chan error)
+		go func() {
+			_, err := c.UpdateCollectionGraph(context.Background(), CollectionGraph{})
+			done <- err
+		}()
+		// Give the first update time to take its lock
+		time.Sleep(50 * time.Millisecond)
+		_, err := otherClient.UpdateCollectionGraph(context.Background(), CollectionGraph{})
+		assert.Nil(t, err)
+		close(otherUpdated)
+
+		assert.Nil(t, <-done)
+	})
+
+	t.Run("A rejected change only fails its caller", func(t *testing.T) {
+		var puts int32
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/collection/graph", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPut {
+				atomic.AddInt32(&puts, 1)
+				var update CollectionGraph
+				_ = json.NewDecoder(r.Body).Decode(&update)
+				for _, collections := range update.Groups {
+					for _, access := range collections {
+						if access == "admin" {
+							w.WriteHeader(http.StatusBadRequest)
+							_, _ = w.Write([]byte(`{"message": "Invalid permissions graph"}`))
+							return
+						}
+					}
+				}
+				_ = json.NewEncoder(w).Encode(update)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(CollectionGraph{Revision: 1})
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+		c := Client{
+			BaseURL:          svr.URL,
+			HTTPClient:       &http.Client{},
+			GraphBatchWindow: 50 * time.Millisecond,
+		}
+
+		var wg sync.WaitGroup
+		var validErr, invalidErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, validErr = c.UpdateCollectionGraph(context.Background(), CollectionGraph{
+				Groups: map[string]map[string]string{"4": {"10": "read"}},
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			_, invalidErr = c.UpdateCollectionGraph(context.Background(), CollectionGraph{
+				Groups: map[string]map[string]string{"4": {"11": "admin"}},
+			})
+		}()
+		wg.Wait()
+
+		assert.Nil(t, validErr)
+		assert.True(t, HasStatusCode(invalidErr, http.StatusBadRequest))
+		// The batch, then each change on its own
+		assert.Equal(t, int32(3), puts)
+	})
+
+	t.Run("Cancelling every caller stops the update", func(t *testing.T) {
+		aborted := make(chan struct{})
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/collection/graph", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPut {
+				// The closed connection is only noticed once the body is read
+				_, _ = io.Copy(io.Discard, r.Body)
+				select {
+				case <-r.Context().Done():
+					close(aborted)
+				case <-time.After(5 * time.Second):
+				}
+				return
+			}
+			_ = json.NewEncoder(w).Encode(CollectionGraph{Revision: 1})
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+		c := Client{
+			BaseURL:          svr.URL,
+			HTTPClient:       &http.Client{},
+			GraphBatchWindow: 50 * time.Millisecond,
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(context.Background(), time.Duration(150+i*100)*time.Millisecond)
+				defer cancel()
+				_, err := c.UpdateCollectionGraph(ctx, CollectionGraph{
+					Groups: map[string]map[string]string{"4": {strconv.Itoa(i + 10): "read"}},
+				})
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+			}(i)
+		}
+		wg.Wait()
+
+		select {
+		case <-aborted:
+		case <-time.After(2 * time.Second):
+			assert.Fail(t, "the update wasn't cancelled")
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -9,6 +10,8 @@ import (
 )
 
 func TestCollection(t *testing.T) {
+	t.Parallel()
+
 	t.Run("Get collection", func(t *testing.T) {
 		expected := Collections{
 			{
@@ -32,12 +35,60 @@ func TestCollection(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		col, err := c.GetCollections()
+		col, err := c.GetCollections(context.Background())
 
 		assert.Nil(t, err)
 		assert.Equal(t, expected, col)
 	})
 
+	t.Run("Skip the root collection", func(t *testing.T) {
+		svr := server("/api/collection", http.MethodGet, []map[string]interface{}{
+			{"id": "root", "name": "Our analytics"},
+			{"id": 1, "name": "TestCollection", "location": "/"},
+		})
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		col, err := c.GetCollections(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, Collections{{Id: 1, Name: "TestCollection", Location: "/"}}, col)
+	})
+
+	t.Run("Descendants", func(t *testing.T) {
+		cols := Collections{
+			{Id: 1, Location: "/"},
+			{Id: 2, Location: "/1/"},
+			{Id: 3, Location: "/1/2/"},
+			{Id: 4, Location: "/"},
+			{Id: 11, Location: "/4/"},
+		}
+
+		assert.Equal(t, []int{2, 3}, cols.Descendants(1))
+		assert.Equal(t, []int{3}, cols.Descendants(2))
+		assert.Nil(t, cols.Descendants(3))
+	})
+
+	t.Run("Parent", func(t *testing.T) {
+		assert.Equal(t, 0, Collection{Id: 1, Location: "/"}.Parent())
+		assert.Equal(t, 2, Collection{Id: 3, Location: "/1/2/"}.Parent())
+	})
+
+	t.Run("Path", func(t *testing.T) {
+		cols := Collections{
+			{Id: 1, Name: "Finance", Location: "/"},
+			{Id: 2, Name: "Reports", Location: "/1/"},
+		}
+
+		assert.Equal(t, "Finance", cols.Path(cols[0]))
+		assert.Equal(t, "Finance/Reports", cols.Path(cols[1]))
+		assert.Equal(t, "Finance/Reports/Monthly", cols.Path(Collection{Id: 3, Name: "Monthly", Location: "/1/2/"}))
+	})
+
 	t.Run("Get collection by id", func(t *testing.T) {
 		collectionId := "root"
 		expected := Collection{
@@ -56,7 +107,7 @@ func TestCollection(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		col, err := c.GetCollection(collectionId)
+		col, err := c.GetCollection(context.Background(), collectionId)
 
 		assert.Nil(t, err)
 		assert.Equal(t, expected, col)
@@ -82,7 +133,7 @@ func TestCollection(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		col, err := c.CreateCollection(collectionToBeCreated)
+		col, err := c.CreateCollection(context.Background(), collectionToBeCreated)
 
 		assert.Nil(t, err)
 		assert.Equal(t, expected, col)
@@ -108,7 +159,7 @@ func TestCollection(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		col, err := c.UpdateCollection(collectionToBeUpdated)
+		col, err := c.UpdateCollection(context.Background(), collectionToBeUpdated)
 
 		assert.Nil(t, err)
 		assert.Equal(t, expected, col)
@@ -133,7 +184,7 @@ func TestCollection(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		col, err := c.UpdateCollection(collectionToBeArchived)
+		col, err := c.UpdateCollection(context.Background(), collectionToBeArchived)
 
 		assert.Nil(t, err)
 		assert.Equal(t, expected, col)
@@ -158,9 +209,9 @@ func TestCollection(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		orig, errOrig := c.GetCollections()
+		orig, errOrig := c.GetCollections(context.Background())
 		svr.Close() // close so the mock server is not running
-		later, errLater := c.GetCollections()
+		later, errLater := c.GetCollections(context.Background())
 
 		assert.Nil(t, errOrig)
 		assert.Nil(t, errLater)
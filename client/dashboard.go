@@ -0,0 +1,127 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+type Dashboard struct {
+	Id           int     `json:"id,omitempty"`
+	Name         string  `json:"name"`
+	Description  *string `json:"description"`
+	CollectionId *int    `json:"collection_id"`
+	// Parameters are the dashboard filters, kept as raw JSON.
+	Parameters json.RawMessage `json:"parameters"`
+	Tabs       []DashboardTab  `json:"tabs"`
+	Dashcards  []Dashcard      `json:"dashcards"`
+	Archived   bool            `json:"archived"`
+}
+
+// DashboardTab and Dashcard ids are negative for the tabs and cards to be created by a dashboard update.
+type DashboardTab struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+}
+
+type Dashcard struct {
+	Id             int  `json:"id"`
+	CardId         *int `json:"card_id"`
+	DashboardTabId *int `json:"dashboard_tab_id"`
+	Row            int  `json:"row"`
+	Col            int  `json:"col"`
+	SizeX          int  `json:"size_x"`
+	SizeY          int  `json:"size_y"`
+	// ParameterMappings link the dashboard parameters to the card fields, kept as raw JSON.
+	ParameterMappings     json.RawMessage `json:"parameter_mappings"`
+	VisualizationSettings json.RawMessage `json:"visualization_settings"`
+}
+
+func (c *Client) GetDashboard(ctx context.Context, id int) (Dashboard, error) {
+	url := fmt.Sprintf("%s/api/dashboard/%d", c.BaseURL, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	dashboard := Dashboard{}
+	if err != nil {
+		return dashboard, err
+	}
+	if err := c.sendRequest(req, &dashboard); err != nil {
+		return dashboard, err
+	}
+
+	log.Printf("[INFO] Got dashboard with id[%d]", dashboard.Id)
+	return dashboard, nil
+}
+
+// CreateDashboard creates an empty dashboard, tabs and dashcards are then set with UpdateDashboard.
+func (c *Client) CreateDashboard(ctx context.Context, dashboard Dashboard) (Dashboard, error) {
+	url := fmt.Sprintf("%s/api/dashboard", c.BaseURL)
+	payload := struct {
+		Name         string          `json:"name"`
+		Description  *string         `json:"description"`
+		CollectionId *int            `json:"collection_id"`
+		Parameters   json.RawMessage `json:"parameters,omitempty"`
+	}{
+		Name:         dashboard.Name,
+		Description:  dashboard.Description,
+		CollectionId: dashboard.CollectionId,
+		Parameters:   dashboard.Parameters,
+	}
+	b := new(bytes.Buffer)
+	_ = json.NewEncoder(b).Encode(payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, b)
+	if err != nil {
+		return dashboard, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	created := Dashboard{}
+	if err := c.sendRequest(req, &created); err != nil {
+		return dashboard, err
+	}
+
+	log.Printf("[INFO] Created dashboard '%s' with id[%d]", created.Name, created.Id)
+	return created, nil
+}
+
+// UpdateDashboard replaces the dashboard attributes, tabs and dashcards in one request. Existing tabs and dashcards
+// missing from the dashboard are removed.
+func (c *Client) UpdateDashboard(ctx context.Context, dashboard Dashboard) (Dashboard, error) {
+	url := fmt.Sprintf("%s/api/dashboard/%d", c.BaseURL, dashboard.Id)
+	if dashboard.Tabs == nil {
+		dashboard.Tabs = []DashboardTab{}
+	}
+	if dashboard.Dashcards == nil {
+		dashboard.Dashcards = []Dashcard{}
+	}
+	b := new(bytes.Buffer)
+	_ = json.NewEncoder(b).Encode(dashboard)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, b)
+	if err != nil {
+		return dashboard, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	updated := Dashboard{}
+	if err := c.sendRequest(req, &updated); err != nil {
+		return dashboard, err
+	}
+
+	log.Printf("[INFO] Updated dashboard '%s' with id[%d]", updated.Name, updated.Id)
+	return updated, nil
+}
+
+func (c *Client) DeleteDashboard(ctx context.Context, id int) error {
+	url := fmt.Sprintf("%s/api/dashboard/%d", c.BaseURL, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	if err := c.sendRequest(req, nil); err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Deleted dashboard with id[%d]", id)
+	return nil
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashboard(t *testing.T) {
+	t.Parallel()
+
+	cardId := 3
+	tabId := 10
+
+	t.Run("Get dashboard", func(t *testing.T) {
+		expected := Dashboard{
+			Id:         1,
+			Name:       "Sales",
+			Parameters: json.RawMessage(`[]`),
+			Tabs:       []DashboardTab{{Id: tabId, Name: "Overview", Position: 0}},
+			Dashcards: []Dashcard{{
+				Id:                    5,
+				CardId:                &cardId,
+				DashboardTabId:        &tabId,
+				SizeX:                 4,
+				SizeY:                 3,
+				ParameterMappings:     json.RawMessage(`[]`),
+				VisualizationSettings: json.RawMessage(`{}`),
+			}},
+		}
+		svr := server("/api/dashboard/1", http.MethodGet, expected)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		dashboard, err := c.GetDashboard(context.Background(), 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, dashboard)
+	})
+
+	t.Run("Create dashboard", func(t *testing.T) {
+		var received map[string]interface{}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/dashboard", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&received)
+			_ = json.NewEncoder(w).Encode(Dashboard{Id: 1, Name: "Sales"})
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		dashboard, err := c.CreateDashboard(context.Background(), Dashboard{
+			Name:      "Sales",
+			Dashcards: []Dashcard{{Id: -1, CardId: &cardId}},
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, dashboard.Id)
+		assert.Equal(t, "Sales", received["name"])
+		assert.NotContains(t, received, "dashcards")
+		assert.NotContains(t, received, "parameters")
+	})
+
+	t.Run("Update dashboard sends every tab and dashcard", func(t *testing.T) {
+		var received map[string]interface{}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/dashboard/1", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPut {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewDecoder(r.Body).Decode(&received)
+			_ = json.NewEncoder(w).Encode(Dashboard{Id: 1, Name: "Sales"})
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		_, err := c.UpdateDashboard(context.Background(), Dashboard{Id: 1, Name: "Sales"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{}, received["tabs"])
+		assert.Equal(t, []interface{}{}, received["dashcards"])
+	})
+
+	t.Run("Delete dashboard", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/dashboard/1", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodDelete:
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		err := c.DeleteDashboard(context.Background(), 1)
+
+		assert.Nil(t, err)
+	})
+}
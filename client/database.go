@@ -0,0 +1,101 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// RedactedValue replaces the sensitive connection details, such as passwords, returned by Metabase.
+const RedactedValue = "**MetabasePass**"
+
+type Database struct {
+	Id             int                    `json:"id,omitempty"`
+	Name           string                 `json:"name"`
+	Engine         string                 `json:"engine"`
+	Details        map[string]interface{} `json:"details"`
+	Schedules      *DatabaseSchedules     `json:"schedules,omitempty"`
+	AutoRunQueries bool                   `json:"auto_run_queries"`
+	IsFullSync     bool                   `json:"is_full_sync"`
+}
+
+type DatabaseSchedules struct {
+	MetadataSync     *Schedule `json:"metadata_sync,omitempty"`
+	CacheFieldValues *Schedule `json:"cache_field_values,omitempty"`
+}
+
+type Schedule struct {
+	ScheduleType   string `json:"schedule_type"`
+	ScheduleDay    string `json:"schedule_day,omitempty"`
+	ScheduleFrame  string `json:"schedule_frame,omitempty"`
+	ScheduleHour   int    `json:"schedule_hour"`
+	ScheduleMinute int    `json:"schedule_minute"`
+}
+
+func (c *Client) GetDatabase(ctx context.Context, id int) (Database, error) {
+	url := fmt.Sprintf("%s/api/database/%d", c.BaseURL, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	db := Database{}
+	if err != nil {
+		return db, err
+	}
+	if err := c.sendRequest(req, &db); err != nil {
+		return db, err
+	}
+
+	log.Printf("[INFO] Got database id='%d' name='%s'", db.Id, db.Name)
+	return db, nil
+}
+
+func (c *Client) CreateDatabase(ctx context.Context, db Database) (Database, error) {
+	url := fmt.Sprintf("%s/api/database", c.BaseURL)
+	b := new(bytes.Buffer)
+	_ = json.NewEncoder(b).Encode(db)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, b)
+	if err != nil {
+		return db, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	created := Database{}
+	if err := c.sendRequest(req, &created); err != nil {
+		return db, err
+	}
+
+	log.Printf("[INFO] Created database id='%d' name='%s'", created.Id, created.Name)
+	return created, nil
+}
+
+func (c *Client) UpdateDatabase(ctx context.Context, db Database) (Database, error) {
+	url := fmt.Sprintf("%s/api/database/%d", c.BaseURL, db.Id)
+	b := new(bytes.Buffer)
+	_ = json.NewEncoder(b).Encode(db)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, b)
+	if err != nil {
+		return db, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	updated := Database{}
+	if err := c.sendRequest(req, &updated); err != nil {
+		return db, err
+	}
+
+	log.Printf("[INFO] Updated database id='%d' name='%s'", updated.Id, updated.Name)
+	return updated, nil
+}
+
+func (c *Client) DeleteDatabase(ctx context.Context, id int) error {
+	url := fmt.Sprintf("%s/api/database/%d", c.BaseURL, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	if err := c.sendRequest(req, nil); err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Deleted database with id[%d]", id)
+	return nil
+}
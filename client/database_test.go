@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabase(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Get database", func(t *testing.T) {
+		dbId := 2
+		expected := Database{
+			Id:     dbId,
+			Name:   "Warehouse",
+			Engine: "postgres",
+			Details: map[string]interface{}{
+				"host":     "db.example.com",
+				"password": RedactedValue,
+			},
+			Schedules: &DatabaseSchedules{
+				MetadataSync: &Schedule{ScheduleType: "hourly", ScheduleMinute: 10},
+			},
+			AutoRunQueries: true,
+			IsFullSync:     true,
+		}
+		svr := server(fmt.Sprintf("/api/database/%d", dbId), http.MethodGet, expected)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		db, err := c.GetDatabase(context.Background(), dbId)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, db)
+	})
+
+	t.Run("Create database", func(t *testing.T) {
+		toBeCreated := Database{
+			Name:    "Warehouse",
+			Engine:  "postgres",
+			Details: map[string]interface{}{"host": "db.example.com", "port": float64(5432), "password": "secret"},
+		}
+		var received Database
+		var receivedPassword interface{}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/database", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&received)
+			receivedPassword = received.Details["password"]
+			created := received
+			created.Id = 2
+			created.Details["password"] = RedactedValue
+			_ = json.NewEncoder(w).Encode(created)
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		db, err := c.CreateDatabase(context.Background(), toBeCreated)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "secret", receivedPassword)
+		assert.Equal(t, 2, db.Id)
+		assert.Equal(t, RedactedValue, db.Details["password"])
+	})
+
+	t.Run("Update database", func(t *testing.T) {
+		toBeUpdated := Database{
+			Id:     2,
+			Name:   "Renamed",
+			Engine: "postgres",
+		}
+		svr := server("/api/database/2", http.MethodPut, toBeUpdated)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		db, err := c.UpdateDatabase(context.Background(), toBeUpdated)
+
+		assert.Nil(t, err)
+		assert.Equal(t, toBeUpdated, db)
+	})
+
+	t.Run("Delete database", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/database/2", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodDelete:
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		err := c.DeleteDatabase(context.Background(), 2)
+
+		assert.Nil(t, err)
+	})
+}
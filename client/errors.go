@@ -0,0 +1,166 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// APIError is returned for unsuccessful responses from the Metabase API.
+type APIError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	// Errors holds the validation errors by field name, when Metabase reported any.
+	Errors  map[string]string
+	Message string
+	// Body is the raw response body.
+	Body string
+}
+
+func (e *APIError) Error() string {
+	if e.Errors == nil && e.Message == "" {
+		return fmt.Sprintf("%s %s: status code: %d, error:%s", e.Method, e.URL, e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("%s %s: status code: %d, errors='%+v', message='%s'", e.Method, e.URL, e.StatusCode, e.Errors, e.Message)
+}
+
+// newAPIError builds an APIError out of an unsuccessful response. Metabase answers with either a JSON
+// object holding `errors` and/or `message`, or a plain text body such as "Not found.".
+func newAPIError(req *http.Request, res *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		Body:       string(body),
+	}
+	var errRes struct {
+		Errors  map[string]interface{} `json:"errors"`
+		Message string                 `json:"message"`
+	}
+	if err := json.Unmarshal(body, &errRes); err == nil {
+		apiErr.Message = errRes.Message
+		if errRes.Errors != nil {
+			apiErr.Errors = make(map[string]string, len(errRes.Errors))
+			for field, e := range errRes.Errors {
+				apiErr.Errors[field] = fmt.Sprint(e)
+			}
+		}
+	}
+	return apiErr
+}
+
+// HasStatusCode reports whether err is an APIError with the given status code.
+func HasStatusCode(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+// ErrNotFound is returned by the lookups of objects which can't be fetched by id, e.g. users by email.
+var ErrNotFound = errors.New("not found")
+
+// IsNotFound reports whether err is a 404 from the Metabase API, or a failed lookup.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound) || HasStatusCode(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is a 409 from the Metabase API.
+func IsConflict(err error) bool {
+	return HasStatusCode(err, http.StatusConflict)
+}
+
+// Kinds of login failures, use errors.Is to match a LoginError against them.
+var (
+	ErrInvalidCredentials    = errors.New("invalid username or password")
+	ErrHostUnreachable       = errors.New("metabase host is unreachable")
+	ErrLoginRateLimited      = errors.New("too many login attempts")
+	ErrPasswordLoginDisabled = errors.New("password login is disabled for this account")
+	ErrLoginFailed           = errors.New("login failed")
+)
+
+// LoginError is returned by NewClient when a session could not be created.
+type LoginError struct {
+	Kind       error
+	StatusCode int
+	Message    string
+	Err        error
+}
+
+func (e *LoginError) Error() string {
+	msg := e.Kind.Error()
+	if e.StatusCode != 0 {
+		msg = fmt.Sprintf("%s (status code: %d)", msg, e.StatusCode)
+	}
+	if e.Message != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.Message)
+	}
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %s", msg, e.Err)
+	}
+	return msg
+}
+
+func (e *LoginError) Unwrap() error {
+	return e.Kind
+}
+
+// newLoginError classifies an unsuccessful /api/session response. Metabase reports most failures as
+// field errors, e.g. `{"errors":{"password":"did not match stored password"}}`.
+func newLoginError(statusCode int, body []byte) *LoginError {
+	message := loginErrorMessage(body)
+	lower := strings.ToLower(message)
+
+	var kind error
+	switch {
+	case statusCode == http.StatusTooManyRequests || strings.Contains(lower, "too many attempts"):
+		kind = ErrLoginRateLimited
+	case isSSOOnlyMessage(lower):
+		kind = ErrPasswordLoginDisabled
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusBadRequest:
+		kind = ErrInvalidCredentials
+	default:
+		kind = ErrLoginFailed
+	}
+	return &LoginError{Kind: kind, StatusCode: statusCode, Message: message}
+}
+
+// isSSOOnlyMessage reports whether Metabase refused the password because the account signs in through SSO.
+func isSSOOnlyMessage(message string) bool {
+	if strings.Contains(message, "password login is disabled") || strings.Contains(message, "single sign-on") {
+		return true
+	}
+	for _, word := range strings.FieldsFunc(message, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if word == "sso" {
+			return true
+		}
+	}
+	return false
+}
+
+func loginErrorMessage(body []byte) string {
+	var errRes struct {
+		Errors  map[string]interface{} `json:"errors"`
+		Message string                 `json:"message"`
+	}
+	if err := json.Unmarshal(body, &errRes); err != nil {
+		return strings.TrimSpace(string(body))
+	}
+
+	var parts []string
+	if errRes.Message != "" {
+		parts = append(parts, errRes.Message)
+	}
+	fields := make([]string, 0, len(errRes.Errors))
+	for field := range errRes.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		parts = append(parts, fmt.Sprintf("%s %v", field, errRes.Errors[field]))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func errorServer(url string, status int, body string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc(url, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestAPIError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Plain text not found", func(t *testing.T) {
+		svr := errorServer("/api/user/1", http.StatusNotFound, "Not found.")
+		defer svr.Close()
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		_, err := c.GetUser(context.Background(), 1)
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		assert.Equal(t, http.MethodGet, apiErr.Method)
+		assert.Equal(t, svr.URL+"/api/user/1", apiErr.URL)
+		assert.Equal(t, "Not found.", apiErr.Body)
+		assert.True(t, IsNotFound(err))
+		assert.False(t, IsConflict(err))
+	})
+
+	t.Run("Field errors and message", func(t *testing.T) {
+		body := `{"errors":{"name":"value must be a non-blank string.","color":["invalid"]},"message":"Invalid collection"}`
+		svr := errorServer("/api/collection", http.StatusBadRequest, body)
+		defer svr.Close()
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		_, err := c.CreateCollection(context.Background(), Collection{})
+
+		var apiErr *APIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		assert.Equal(t, http.MethodPost, apiErr.Method)
+		assert.Equal(t, map[string]string{
+			"name":  "value must be a non-blank string.",
+			"color": "[invalid]",
+		}, apiErr.Errors)
+		assert.Equal(t, "Invalid collection", apiErr.Message)
+		assert.Equal(t, body, apiErr.Body)
+		assert.False(t, IsNotFound(err))
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		svr := errorServer("/api/collection/graph", http.StatusConflict, `{"message":"Looks like someone else edited the permissions"}`)
+		defer svr.Close()
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		_, err := c.GetCollectionGraph(context.Background())
+
+		assert.True(t, IsConflict(err))
+		assert.True(t, HasStatusCode(err, http.StatusConflict))
+	})
+
+	t.Run("Helpers see through wrapped errors", func(t *testing.T) {
+		err := fmt.Errorf("reading user: %w", &APIError{StatusCode: http.StatusNotFound})
+
+		assert.True(t, IsNotFound(err))
+		assert.False(t, IsNotFound(errors.New("status code: 404")))
+		assert.False(t, IsNotFound(nil))
+	})
+}
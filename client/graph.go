@@ -0,0 +1,49 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// updateGraph puts a permissions graph built for the current revision. Metabase rejects the update when the revision
+// is not the latest one, in which case it is retried with the new revision according to the client's RetryPolicy.
+func (c *Client) updateGraph(ctx context.Context, url string, currentRevision func(context.Context) (int, error), graph func(revision int) interface{}, v interface{}) error {
+	for retry := 0; ; retry++ {
+		revision, err := currentRevision(ctx)
+		if err != nil {
+			return err
+		}
+		b := new(bytes.Buffer)
+		_ = json.NewEncoder(b).Encode(graph(revision))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, b)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		err = c.sendRequest(req, v)
+		if err == nil {
+			return nil
+		}
+		// Someone else updated the graph in between, retry with the new revision
+		if !isRevisionConflict(err) || retry >= c.Retry.MaxRetries {
+			return err
+		}
+		wait := c.Retry.backoff(retry, nil)
+		log.Printf("[ERROR] There were an error with the graph '%s', retrying in %s (%d/%d).", url, wait, retry+1, c.Retry.MaxRetries)
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// isRevisionConflict reports whether the graph was updated concurrently. Besides a 409, older Metabase versions fail
+// with a primary key violation when two updates create the same revision.
+func isRevisionConflict(err error) bool {
+	var apiErr *APIError
+	return IsConflict(err) || errors.As(err, &apiErr) && strings.Contains(apiErr.Body, "revision_pkey")
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -21,10 +22,10 @@ type groupMembership struct {
 	MembershipId int `json:"membership_id"`
 }
 
-func (c *Client) GetMemberships() (Memberships, error) {
+func (c *Client) GetMemberships(ctx context.Context) (Memberships, error) {
 	var memberships Memberships
 	url := fmt.Sprintf("%s/api/permissions/membership", c.BaseURL)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return memberships, err
 	}
@@ -36,13 +37,13 @@ func (c *Client) GetMemberships() (Memberships, error) {
 	return memberships, nil
 }
 
-func (c *Client) CreateMembership(m Membership) (Membership, error) {
+func (c *Client) CreateMembership(ctx context.Context, m Membership) (Membership, error) {
 	var created Membership
 	var gm []groupMembership
 	url := fmt.Sprintf("%s/api/permissions/membership", c.BaseURL)
 	b := new(bytes.Buffer)
 	_ = json.NewEncoder(b).Encode(m)
-	req, err := http.NewRequest(http.MethodPost, url, b)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, b)
 	req.Header.Set("Content-Type", "application/json")
 	if err != nil {
 		return created, err
@@ -66,9 +67,9 @@ func (c *Client) CreateMembership(m Membership) (Membership, error) {
 	return created, errors.New("something went wrong in membership creation")
 }
 
-func (c *Client) DeleteMembership(membershipId int) error {
+func (c *Client) DeleteMembership(ctx context.Context, membershipId int) error {
 	url := fmt.Sprintf("%s/api/permissions/membership/%d", c.BaseURL, membershipId)
-	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +11,8 @@ import (
 )
 
 func TestGroupMembership(t *testing.T) {
+	t.Parallel()
+
 	t.Run("Get Memberships", func(t *testing.T) {
 		expected := Memberships{
 			1: []Membership{
@@ -30,7 +33,7 @@ func TestGroupMembership(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		us, err := c.GetMemberships()
+		us, err := c.GetMemberships(context.Background())
 
 		assert.Nil(t, err)
 		assert.Equal(t, expected, us)
@@ -61,7 +64,7 @@ func TestGroupMembership(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		us, err := c.CreateMembership(membershipToBeCreated)
+		us, err := c.CreateMembership(context.Background(), membershipToBeCreated)
 
 		assert.Nil(t, err)
 		assert.Equal(t, expected, us)
@@ -87,7 +90,7 @@ func TestGroupMembership(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		err := c.DeleteMembership(membershipId)
+		err := c.DeleteMembership(context.Background(), membershipId)
 
 		assert.Nil(t, err)
 	})
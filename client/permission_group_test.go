@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -9,6 +10,8 @@ import (
 )
 
 func TestPermissionGroups(t *testing.T) {
+	t.Parallel()
+
 	t.Run("Get PermissionGroups", func(t *testing.T) {
 		expected := PermissionGroups{{
 			Id:          1,
@@ -24,7 +27,7 @@ func TestPermissionGroups(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		groups, err := c.GetPermissionGroups()
+		groups, err := c.GetPermissionGroups(context.Background())
 
 		assert.Nil(t, err)
 		assert.Equal(t, expected, groups)
@@ -43,7 +46,7 @@ func TestPermissionGroups(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		group, err := c.CreatePermissionGroup("test-client")
+		group, err := c.CreatePermissionGroup(context.Background(), "test-client")
 
 		assert.Nil(t, err)
 		assert.Equal(t, expected, group)
@@ -64,7 +67,27 @@ func TestPermissionGroups(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		group, err := c.GetPermissionGroup(groupId)
+		group, err := c.GetPermissionGroup(context.Background(), groupId)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, group)
+	})
+
+	t.Run("Update PermissionGroup", func(t *testing.T) {
+		expected := PermissionGroup{
+			Id:   1,
+			Name: "Renamed Group",
+		}
+		url := fmt.Sprintf("/api/permissions/group/%d", expected.Id)
+		httpMethod := http.MethodPut
+		svr := server(url, httpMethod, expected)
+		defer svr.Close()
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		group, err := c.UpdatePermissionGroup(context.Background(), expected)
 
 		assert.Nil(t, err)
 		assert.Equal(t, expected, group)
@@ -82,7 +105,7 @@ func TestPermissionGroups(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		err := c.DeletePermissionGroup(groupId)
+		err := c.DeletePermissionGroup(context.Background(), groupId)
 
 		assert.Nil(t, err)
 	})
@@ -100,9 +123,9 @@ func TestPermissionGroups(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		orig, errOrig := c.GetPermissionGroups()
+		orig, errOrig := c.GetPermissionGroups(context.Background())
 		svr.Close() // close so the mock server is not running
-		later, errLater := c.GetPermissionGroups()
+		later, errLater := c.GetPermissionGroups(context.Background())
 
 		assert.Nil(t, errOrig)
 		assert.Nil(t, errLater)
@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -19,13 +20,15 @@ type PermissionGroups []struct {
 	MemberCount int    `json:"member_count"`
 }
 
-func (c *Client) GetPermissionGroups() (PermissionGroups, error) {
-	if c.permissionGroups != nil {
-		return *c.permissionGroups, nil
-	}
+func (c *Client) GetPermissionGroups(ctx context.Context) (PermissionGroups, error) {
+	return c.permissionGroups.get(c.Cache, func() (PermissionGroups, error) {
+		return c.getPermissionGroups(ctx)
+	})
+}
 
+func (c *Client) getPermissionGroups(ctx context.Context) (PermissionGroups, error) {
 	url := fmt.Sprintf("%s/api/permissions/group", c.BaseURL)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -34,14 +37,14 @@ func (c *Client) GetPermissionGroups() (PermissionGroups, error) {
 		return nil, err
 	}
 
-	c.permissionGroups = &pg
+	log.Printf("[DEBUG] Got permissionGroups '%+v'", pg)
 	return pg, nil
 }
 
-func (c *Client) GetPermissionGroup(id int) (PermissionGroup, error) {
+func (c *Client) GetPermissionGroup(ctx context.Context, id int) (PermissionGroup, error) {
 	url := fmt.Sprintf("%s/api/permissions/group/%d", c.BaseURL, id)
 	pg := PermissionGroup{}
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return pg, err
 	}
@@ -54,12 +57,14 @@ func (c *Client) GetPermissionGroup(id int) (PermissionGroup, error) {
 	return pg, nil
 }
 
-func (c *Client) CreatePermissionGroup(name string) (PermissionGroup, error) {
+func (c *Client) CreatePermissionGroup(ctx context.Context, name string) (PermissionGroup, error) {
+	defer c.permissionGroups.invalidate()
+
 	url := fmt.Sprintf("%s/api/permissions/group", c.BaseURL)
 	pg := PermissionGroup{Name: name}
 	b := new(bytes.Buffer)
 	_ = json.NewEncoder(b).Encode(pg)
-	req, err := http.NewRequest(http.MethodPost, url, b)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, b)
 	req.Header.Set("Content-Type", "application/json")
 	if err != nil {
 		return pg, err
@@ -72,9 +77,32 @@ func (c *Client) CreatePermissionGroup(name string) (PermissionGroup, error) {
 	return pg, nil
 }
 
-func (c *Client) DeletePermissionGroup(id int) error {
+// UpdatePermissionGroup renames the permission group, keeping its members and permissions.
+func (c *Client) UpdatePermissionGroup(ctx context.Context, pg PermissionGroup) (PermissionGroup, error) {
+	defer c.permissionGroups.invalidate()
+
+	url := fmt.Sprintf("%s/api/permissions/group/%d", c.BaseURL, pg.Id)
+	b := new(bytes.Buffer)
+	_ = json.NewEncoder(b).Encode(pg)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, b)
+	if err != nil {
+		return pg, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	updated := PermissionGroup{}
+	if err := c.sendRequest(req, &updated); err != nil {
+		return pg, err
+	}
+
+	log.Printf("[INFO] Updated permissionGroup '%+v'", updated)
+	return updated, nil
+}
+
+func (c *Client) DeletePermissionGroup(ctx context.Context, id int) error {
+	defer c.permissionGroups.invalidate()
+
 	url := fmt.Sprintf("%s/api/permissions/group/%d", c.BaseURL, id)
-	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
 	if err != nil {
 		return err
 	}
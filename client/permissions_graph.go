@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+)
+
+// PermissionsGraph is the data permissions graph, keyed by group id and then by database id.
+type PermissionsGraph struct {
+	Revision int                                       `json:"revision"`
+	Groups   map[string]map[string]DatabasePermissions `json:"groups"`
+}
+
+// DatabasePermissions holds a group's permissions on a database. Each permission is either a level granted on the
+// whole database, e.g. "query-builder", or a map of levels by schema name, the level of a schema being itself either
+// a level or a map of levels by table id.
+type DatabasePermissions struct {
+	ViewData      interface{}          `json:"view-data,omitempty"`
+	CreateQueries interface{}          `json:"create-queries,omitempty"`
+	Download      *DownloadPermissions `json:"download,omitempty"`
+}
+
+type DownloadPermissions struct {
+	Schemas interface{} `json:"schemas"`
+}
+
+func (c *Client) GetPermissionsGraph(ctx context.Context) (PermissionsGraph, error) {
+	url := fmt.Sprintf("%s/api/permissions/graph", c.BaseURL)
+	return c.getPermissionsGraph(ctx, url)
+}
+
+// GetGroupPermissionsGraph returns the slice of the data permissions graph for a single group.
+func (c *Client) GetGroupPermissionsGraph(ctx context.Context, groupId int) (PermissionsGraph, error) {
+	url := fmt.Sprintf("%s/api/permissions/graph/group/%d", c.BaseURL, groupId)
+	return c.getPermissionsGraph(ctx, url)
+}
+
+func (c *Client) getPermissionsGraph(ctx context.Context, url string) (PermissionsGraph, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	permissionsGraph := PermissionsGraph{}
+	if err != nil {
+		return permissionsGraph, err
+	}
+	if err := c.sendRequest(req, &permissionsGraph); err != nil {
+		return permissionsGraph, err
+	}
+
+	log.Printf("[DEBUG] Got permissions graph '%+v'", permissionsGraph)
+	return permissionsGraph, nil
+}
+
+// UpdatePermissionsGraph updates the groups and databases present in the given graph, leaving the others untouched.
+// The returned graph only holds the new revision.
+func (c *Client) UpdatePermissionsGraph(ctx context.Context, pg PermissionsGraph) (PermissionsGraph, error) {
+	c.permissionsGraphMu.Lock()
+	defer c.permissionsGraphMu.Unlock()
+
+	url := fmt.Sprintf("%s/api/permissions/graph?skip-graph=true", c.BaseURL)
+
+	// The revision is the same in every slice of the graph, so only read the slice of a group being updated
+	groupIds := make([]string, 0, len(pg.Groups))
+	for groupId := range pg.Groups {
+		groupIds = append(groupIds, groupId)
+	}
+	sort.Strings(groupIds)
+	currentRevision := func(ctx context.Context) (int, error) {
+		var current PermissionsGraph
+		var err error
+		if len(groupIds) == 0 {
+			current, err = c.GetPermissionsGraph(ctx)
+		} else {
+			current, err = c.getPermissionsGraph(ctx, fmt.Sprintf("%s/api/permissions/graph/group/%s", c.BaseURL, groupIds[0]))
+		}
+		return current.Revision, err
+	}
+
+	updated := PermissionsGraph{}
+	err := c.updateGraph(ctx, url, currentRevision, func(revision int) interface{} {
+		pg.Revision = revision
+		return pg
+	}, &updated)
+	if err != nil {
+		return pg, err
+	}
+
+	log.Printf("[INFO] Updated permissions graph to revision %d", updated.Revision)
+	return updated, nil
+}
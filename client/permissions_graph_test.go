@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPermissionsGraph() PermissionsGraph {
+	return PermissionsGraph{
+		Revision: 4,
+		Groups: map[string]map[string]DatabasePermissions{
+			"1": {
+				"2": {
+					ViewData:      "unrestricted",
+					CreateQueries: "query-builder-and-native",
+					Download:      &DownloadPermissions{Schemas: "full"},
+				},
+			},
+			"3": {
+				"2": {
+					ViewData: "unrestricted",
+					CreateQueries: map[string]interface{}{
+						"public": "query-builder",
+						"sales":  map[string]interface{}{"12": "query-builder"},
+					},
+					Download: &DownloadPermissions{Schemas: "none"},
+				},
+			},
+		},
+	}
+}
+
+func TestPermissionsGraph(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Get permissions graph", func(t *testing.T) {
+		expected := testPermissionsGraph()
+		svr := server("/api/permissions/graph", http.MethodGet, expected)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		pg, err := c.GetPermissionsGraph(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, pg)
+	})
+
+	t.Run("Get the graph of a single group", func(t *testing.T) {
+		var updates []PermissionsGraph
+		svr := permissionsGraphMockServer(testPermissionsGraph(), &updates)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		pg, err := c.GetGroupPermissionsGraph(context.Background(), 3)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 4, pg.Revision)
+		assert.Len(t, pg.Groups, 1)
+		assert.Equal(t, testPermissionsGraph().Groups["3"], pg.Groups["3"])
+	})
+
+	t.Run("Update only the given slice of the graph", func(t *testing.T) {
+		var updates []PermissionsGraph
+		svr := permissionsGraphMockServer(testPermissionsGraph(), &updates)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+		slice := PermissionsGraph{Groups: map[string]map[string]DatabasePermissions{
+			"3": {"2": {CreateQueries: "no", Download: &DownloadPermissions{Schemas: "none"}}},
+		}}
+
+		updated, err := c.UpdatePermissionsGraph(context.Background(), slice)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 5, updated.Revision)
+		assert.Len(t, updates, 1)
+		assert.Equal(t, 4, updates[0].Revision)
+		assert.Equal(t, slice.Groups, updates[0].Groups)
+
+		pg, _ := c.GetPermissionsGraph(context.Background())
+		assert.Equal(t, "no", pg.Groups["3"]["2"].CreateQueries)
+		assert.Equal(t, "query-builder-and-native", pg.Groups["1"]["2"].CreateQueries)
+	})
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how requests failing with a transient error are retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt, 0 disables retries.
+	MaxRetries int
+	// WaitMin is the wait before the first retry, doubled on each following retry.
+	WaitMin time.Duration
+	// WaitMax caps the wait between retries, including waits requested through Retry-After.
+	WaitMax time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 4,
+	WaitMin:    time.Second,
+	WaitMax:    30 * time.Second,
+}
+
+// shouldRetry reports whether a request can be retried given its outcome. Requests throttled with a 429 are
+// retried whatever their method as Metabase did not process them. Network errors and gateway errors are only
+// retried for idempotent methods, since a POST may have been applied before the error.
+func shouldRetry(req *http.Request, res *http.Response, err error) bool {
+	idempotent := isIdempotent(req.Method)
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return idempotent
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// backoff returns how long to wait before the given retry (0 for the first one). The exponential backoff is
+// jittered so that concurrent resources don't retry in lockstep, and a Retry-After header takes precedence.
+func (p RetryPolicy) backoff(retry int, res *http.Response) time.Duration {
+	if res != nil {
+		if wait, ok := retryAfter(res.Header.Get("Retry-After")); ok {
+			return p.capWait(wait)
+		}
+	}
+	wait := float64(p.WaitMin) * math.Pow(2, float64(retry))
+	if wait > float64(p.WaitMax) {
+		wait = float64(p.WaitMax)
+	}
+	jittered := wait/2 + rand.Float64()*wait/2
+	return p.capWait(time.Duration(jittered))
+}
+
+func (p RetryPolicy) capWait(wait time.Duration) time.Duration {
+	if p.WaitMax > 0 && wait > p.WaitMax {
+		return p.WaitMax
+	}
+	return wait
+}
+
+// retryAfter parses a Retry-After header, given either in seconds or as an HTTP date.
+func retryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// do sends the request, retrying it according to the client's RetryPolicy.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	for retry := 0; ; retry++ {
+		res, err := c.HTTPClient.Do(req)
+		if retry >= c.Retry.MaxRetries || !shouldRetry(req, res, err) {
+			return res, err
+		}
+
+		wait := c.Retry.backoff(retry, res)
+		if err != nil {
+			log.Printf("[WARN] Request %s %s failed: %s, retrying in %s (%d/%d)", req.Method, req.URL, err, wait, retry+1, c.Retry.MaxRetries)
+		} else {
+			log.Printf("[WARN] Request %s %s got status %d, retrying in %s (%d/%d)", req.Method, req.URL, res.StatusCode, wait, retry+1, c.Retry.MaxRetries)
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+
+		if req, err = replayRequest(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleep waits for the given duration, returning early with an error if the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func flakyServer(url string, failures int, status int, header http.Header, expected interface{}) (*httptest.Server, *int) {
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc(url, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(expected)
+	})
+	return httptest.NewServer(mux), &calls
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{MaxRetries: 3, WaitMin: time.Millisecond, WaitMax: 10 * time.Millisecond}
+
+	t.Run("Retry idempotent requests on 503", func(t *testing.T) {
+		expected := PermissionGroup{Id: 1, Name: "Test Group"}
+		svr, calls := flakyServer("/api/permissions/group/1", 2, http.StatusServiceUnavailable, nil, expected)
+		defer svr.Close()
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+			Retry:      policy,
+		}
+
+		group, err := c.GetPermissionGroup(context.Background(), 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, group)
+		assert.Equal(t, 3, *calls)
+	})
+
+	t.Run("Give up after max retries", func(t *testing.T) {
+		svr, calls := flakyServer("/api/permissions/group/1", 10, http.StatusGatewayTimeout, nil, nil)
+		defer svr.Close()
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+			Retry:      policy,
+		}
+
+		_, err := c.GetPermissionGroup(context.Background(), 1)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, 4, *calls)
+	})
+
+	t.Run("Do not retry POST on 502", func(t *testing.T) {
+		svr, calls := flakyServer("/api/permissions/group", 1, http.StatusBadGateway, nil, PermissionGroup{Id: 1})
+		defer svr.Close()
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+			Retry:      policy,
+		}
+
+		_, err := c.CreatePermissionGroup(context.Background(), "Test Group")
+
+		assert.NotNil(t, err)
+		assert.Equal(t, 1, *calls)
+	})
+
+	t.Run("Retry POST on 429 honoring Retry-After", func(t *testing.T) {
+		expected := PermissionGroup{Id: 1, Name: "Test Group"}
+		header := http.Header{"Retry-After": []string{"0"}}
+		svr, calls := flakyServer("/api/permissions/group", 1, http.StatusTooManyRequests, header, expected)
+		defer svr.Close()
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+			Retry:      RetryPolicy{MaxRetries: 1, WaitMin: time.Hour, WaitMax: time.Hour},
+		}
+
+		group, err := c.CreatePermissionGroup(context.Background(), "Test Group")
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, group)
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("No retries with the zero policy", func(t *testing.T) {
+		svr, calls := flakyServer("/api/permissions/group/1", 1, http.StatusServiceUnavailable, nil, nil)
+		defer svr.Close()
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		_, err := c.GetPermissionGroup(context.Background(), 1)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, 1, *calls)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{MaxRetries: 5, WaitMin: time.Second, WaitMax: 4 * time.Second}
+
+	t.Run("Exponential backoff capped at WaitMax", func(t *testing.T) {
+		for retry, max := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+			wait := policy.backoff(retry, nil)
+			assert.LessOrEqual(t, wait, max)
+			assert.GreaterOrEqual(t, wait, max/2)
+		}
+	})
+
+	t.Run("Retry-After in seconds", func(t *testing.T) {
+		res := &http.Response{Header: http.Header{"Retry-After": []string{"3"}}}
+
+		assert.Equal(t, 3*time.Second, policy.backoff(0, res))
+	})
+
+	t.Run("Retry-After is capped at WaitMax", func(t *testing.T) {
+		res := &http.Response{Header: http.Header{"Retry-After": []string{"120"}}}
+
+		assert.Equal(t, 4*time.Second, policy.backoff(0, res))
+	})
+
+	t.Run("Retry-After as an HTTP date", func(t *testing.T) {
+		date := time.Now().Add(2 * time.Second).UTC().Format(http.TimeFormat)
+		res := &http.Response{Header: http.Header{"Retry-After": []string{date}}}
+
+		wait := policy.backoff(0, res)
+		assert.LessOrEqual(t, wait, 2*time.Second)
+		assert.Greater(t, wait, time.Duration(0))
+	})
+}
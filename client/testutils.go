@@ -5,6 +5,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 )
 
 func server(url string, httpMethod string, expected interface{}) *httptest.Server {
@@ -22,6 +24,7 @@ func server(url string, httpMethod string, expected interface{}) *httptest.Serve
 }
 
 func graphUpdateMockServer() *httptest.Server {
+	var mu sync.Mutex
 	mux := http.NewServeMux()
 
 	cg := CollectionGraph{
@@ -40,6 +43,8 @@ func graphUpdateMockServer() *httptest.Server {
 	}
 
 	mux.HandleFunc("/api/collection/graph", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
 		switch r.Method {
 		case http.MethodGet:
 			_ = json.NewEncoder(w).Encode(cg)
@@ -67,3 +72,54 @@ func graphUpdateMockServer() *httptest.Server {
 	svr := httptest.NewServer(mux)
 	return svr
 }
+
+// permissionsGraphMockServer serves the data permissions graph and records the graphs it is updated with.
+func permissionsGraphMockServer(pg PermissionsGraph, updates *[]PermissionsGraph) *httptest.Server {
+	var mu sync.Mutex
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/api/permissions/graph", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(pg)
+		case http.MethodPut:
+			var update PermissionsGraph
+			if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if update.Revision != pg.Revision {
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(`{"message":"Looks like someone else edited the permissions and your data is out of date."}`))
+				return
+			}
+			*updates = append(*updates, update)
+			for groupId, dbs := range update.Groups {
+				if pg.Groups[groupId] == nil {
+					pg.Groups[groupId] = map[string]DatabasePermissions{}
+				}
+				for dbId, perms := range dbs {
+					pg.Groups[groupId][dbId] = perms
+				}
+			}
+			pg.Revision++
+			_ = json.NewEncoder(w).Encode(PermissionsGraph{Revision: pg.Revision})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+	mux.HandleFunc("/api/permissions/graph/group/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		groupId := strings.TrimPrefix(r.URL.Path, "/api/permissions/graph/group/")
+		slice := PermissionsGraph{Revision: pg.Revision, Groups: map[string]map[string]DatabasePermissions{}}
+		if dbs, found := pg.Groups[groupId]; found {
+			slice.Groups[groupId] = dbs
+		}
+		_ = json.NewEncoder(w).Encode(slice)
+	})
+
+	return httptest.NewServer(mux)
+}
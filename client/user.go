@@ -2,45 +2,96 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
 type User struct {
-	Id        int    `json:"id"`
-	Email     string `json:"email"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	Id          int    `json:"id"`
+	Email       string `json:"email"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	IsSuperuser bool   `json:"is_superuser"`
+	// LoginAttributes are used by data sandboxing, Metabase stores them as any JSON value.
+	LoginAttributes map[string]interface{} `json:"login_attributes"`
+	// Locale is null when the user follows the site locale.
+	Locale *string `json:"locale"`
+	// IsActive is false for deleted users, Metabase only deactivates them.
+	IsActive bool `json:"is_active"`
 }
 
 type Users struct {
-	Data []User `json:"data"`
+	Data  []User `json:"data"`
+	Total int    `json:"total"`
 }
 
-func (c *Client) GetUsers() (Users, error) {
-	if c.users != nil {
-		return *c.users, nil
+// UserQuery filters the users listed by GetUsers.
+type UserQuery struct {
+	// Query matches the users by name or email.
+	Query              string
+	IncludeDeactivated bool
+}
+
+// usersPageSize is the number of users fetched per request by GetUsers.
+const usersPageSize = 50
+
+// GetUsers lists the users matching the query, one page at a time.
+func (c *Client) GetUsers(ctx context.Context, q UserQuery) (Users, error) {
+	users := Users{Data: []User{}}
+	for {
+		params := url.Values{}
+		params.Set("limit", strconv.Itoa(usersPageSize))
+		params.Set("offset", strconv.Itoa(len(users.Data)))
+		if q.Query != "" {
+			params.Set("query", q.Query)
+		}
+		if q.IncludeDeactivated {
+			params.Set("include_deactivated", "true")
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/user?%s", c.BaseURL, params.Encode()), nil)
+		if err != nil {
+			return users, err
+		}
+		page := Users{}
+		if err := c.sendRequest(req, &page); err != nil {
+			return users, err
+		}
+		users.Data = append(users.Data, page.Data...)
+		users.Total = page.Total
+
+		// Metabase versions without pagination return every user and no total
+		if len(page.Data) == 0 || len(users.Data) >= page.Total {
+			break
+		}
 	}
-	url := fmt.Sprintf("%s/api/user", c.BaseURL)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	users := Users{}
+
+	log.Printf("[DEBUG] Got %d users matching '%+v'", len(users.Data), q)
+	return users, nil
+}
+
+// GetUserByEmail returns the user with the given email, or an error matching ErrNotFound.
+func (c *Client) GetUserByEmail(ctx context.Context, email string, includeDeactivated bool) (User, error) {
+	users, err := c.GetUsers(ctx, UserQuery{Query: email, IncludeDeactivated: includeDeactivated})
 	if err != nil {
-		return users, err
+		return User{}, err
 	}
-	if err := c.sendRequest(req, &users); err != nil {
-		return users, err
+	for _, user := range users.Data {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
 	}
-
-	log.Printf("[DEBUG] Got users '%+v'", users)
-	c.users = &users
-	return users, nil
+	return User{}, fmt.Errorf("user with email '%s': %w", email, ErrNotFound)
 }
 
-func (c *Client) GetUser(id int) (User, error) {
+func (c *Client) GetUser(ctx context.Context, id int) (User, error) {
 	url := fmt.Sprintf("%s/api/user/%d", c.BaseURL, id)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	user := User{}
 	if err != nil {
 		return user, err
@@ -53,11 +104,11 @@ func (c *Client) GetUser(id int) (User, error) {
 	return user, nil
 }
 
-func (c *Client) CreateUser(u User) (User, error) {
+func (c *Client) CreateUser(ctx context.Context, u User) (User, error) {
 	url := fmt.Sprintf("%s/api/user", c.BaseURL)
 	b := new(bytes.Buffer)
 	_ = json.NewEncoder(b).Encode(u)
-	req, err := http.NewRequest(http.MethodPost, url, b)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, b)
 	req.Header.Set("Content-Type", "application/json")
 	if err != nil {
 		return u, err
@@ -71,11 +122,11 @@ func (c *Client) CreateUser(u User) (User, error) {
 	return created, nil
 }
 
-func (c *Client) UpdateUser(u User, id int) (User, error) {
+func (c *Client) UpdateUser(ctx context.Context, u User, id int) (User, error) {
 	url := fmt.Sprintf("%s/api/user/%d", c.BaseURL, id)
 	b := new(bytes.Buffer)
 	_ = json.NewEncoder(b).Encode(u)
-	req, err := http.NewRequest(http.MethodPut, url, b)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, b)
 	req.Header.Set("Content-Type", "application/json")
 	if err != nil {
 		return u, err
@@ -89,9 +140,9 @@ func (c *Client) UpdateUser(u User, id int) (User, error) {
 	return updated, nil
 }
 
-func (c *Client) DeleteUser(id int) (DeleteSuccess, error) {
+func (c *Client) DeleteUser(ctx context.Context, id int) (DeleteSuccess, error) {
 	url := fmt.Sprintf("%s/api/user/%d", c.BaseURL, id)
-	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
 	resp := DeleteSuccess{}
 	if err != nil {
 		return resp, err
@@ -104,3 +155,18 @@ func (c *Client) DeleteUser(id int) (DeleteSuccess, error) {
 	log.Printf("[INFO] Deleted user by id='%d'", id)
 	return resp, nil
 }
+
+func (c *Client) ReactivateUser(ctx context.Context, id int) (User, error) {
+	url := fmt.Sprintf("%s/api/user/%d/reactivate", c.BaseURL, id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, nil)
+	reactivated := User{}
+	if err != nil {
+		return reactivated, err
+	}
+	if err := c.sendRequest(req, &reactivated); err != nil {
+		return reactivated, err
+	}
+
+	log.Printf("[INFO] Reactivated user by id='%d'", id)
+	return reactivated, nil
+}
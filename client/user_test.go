@@ -1,14 +1,21 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestUser(t *testing.T) {
+	t.Parallel()
+
 	t.Run("Get users", func(t *testing.T) {
 		expected := Users{
 			Data: []User{{
@@ -25,7 +32,7 @@ func TestUser(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		us, err := c.GetUsers()
+		us, err := c.GetUsers(context.Background(), UserQuery{})
 
 		assert.Nil(t, err)
 		assert.Equal(t, expected, us)
@@ -33,11 +40,15 @@ func TestUser(t *testing.T) {
 
 	t.Run("Get user by id", func(t *testing.T) {
 		userId := 1
+		locale := "fr"
 		expected := User{
-			Id:        userId,
-			Email:     "test@example.com",
-			FirstName: "John",
-			LastName:  "Doe",
+			Id:              userId,
+			Email:           "test@example.com",
+			FirstName:       "John",
+			LastName:        "Doe",
+			IsSuperuser:     true,
+			LoginAttributes: map[string]interface{}{"region": "emea"},
+			Locale:          &locale,
 		}
 
 		url := fmt.Sprintf("/api/user/%d", userId)
@@ -50,7 +61,7 @@ func TestUser(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		us, err := c.GetUser(userId)
+		us, err := c.GetUser(context.Background(), userId)
 
 		assert.Nil(t, err)
 		assert.Equal(t, expected, us)
@@ -78,7 +89,7 @@ func TestUser(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		us, err := c.CreateUser(userToBeCreated)
+		us, err := c.CreateUser(context.Background(), userToBeCreated)
 
 		assert.Nil(t, err)
 		assert.Equal(t, expected, us)
@@ -98,7 +109,7 @@ func TestUser(t *testing.T) {
 			LastName:  "Doe",
 		}
 		httpMethod := http.MethodPut
-		svr := server("/api/user", httpMethod, expected)
+		svr := server(fmt.Sprintf("/api/user/%d", userToBeUpdated.Id), httpMethod, expected)
 		defer svr.Close()
 
 		c := Client{
@@ -106,7 +117,7 @@ func TestUser(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		us, err := c.UpdateUser(userToBeUpdated, 1)
+		us, err := c.UpdateUser(context.Background(), userToBeUpdated, 1)
 
 		assert.Nil(t, err)
 		assert.Equal(t, expected, us)
@@ -126,32 +137,104 @@ func TestUser(t *testing.T) {
 			HTTPClient: &http.Client{},
 		}
 
-		us, err := c.DeleteUser(userId)
+		us, err := c.DeleteUser(context.Background(), userId)
 
 		assert.Nil(t, err)
 		assert.Equal(t, expected, us)
 	})
 
-	t.Run("Get users & cache them so that subsequent calls are not making network call", func(t *testing.T) {
-		email := "test@example.com"
+	t.Run("Get users matching a query including deactivated ones", func(t *testing.T) {
 		expected := Users{
-			Data: []User{{
-				Id:    1,
-				Email: email,
-			}},
+			Data:  []User{{Id: 1, Email: "test@example.com", IsActive: false}},
+			Total: 1,
+		}
+		var query url.Values
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/user", func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+			_ = json.NewEncoder(w).Encode(expected)
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
 		}
-		svr := server("/api/user", http.MethodGet, expected)
+
+		us, err := c.GetUsers(context.Background(), UserQuery{Query: "test@example.com", IncludeDeactivated: true})
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, us)
+		assert.Equal(t, "test@example.com", query.Get("query"))
+		assert.Equal(t, "true", query.Get("include_deactivated"))
+	})
+
+	t.Run("Reactivate user", func(t *testing.T) {
+		expected := User{Id: 1, Email: "test@example.com", IsActive: true}
+		svr := server("/api/user/1/reactivate", http.MethodPut, expected)
+		defer svr.Close()
+
 		c := Client{
 			BaseURL:    svr.URL,
 			HTTPClient: &http.Client{},
 		}
 
-		orig, errOrig := c.GetUsers()
-		svr.Close() // close so the mock server is not running
-		later, errLater := c.GetUsers()
+		us, err := c.ReactivateUser(context.Background(), 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, us)
+	})
+
+	t.Run("Get users one page at a time", func(t *testing.T) {
+		all := make([]User, 120)
+		for i := range all {
+			all[i] = User{Id: i + 1, Email: fmt.Sprintf("user%d@example.com", i+1)}
+		}
+		var offsets []string
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/user", func(w http.ResponseWriter, r *http.Request) {
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			offsets = append(offsets, r.URL.Query().Get("offset"))
+			end := offset + limit
+			if end > len(all) {
+				end = len(all)
+			}
+			_ = json.NewEncoder(w).Encode(Users{Data: all[offset:end], Total: len(all)})
+		})
+		svr := httptest.NewServer(mux)
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		us, err := c.GetUsers(context.Background(), UserQuery{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, all, us.Data)
+		assert.Equal(t, []string{"0", "50", "100"}, offsets)
+	})
+
+	t.Run("Get user by email", func(t *testing.T) {
+		svr := server("/api/user", http.MethodGet, Users{
+			Data:  []User{{Id: 1, Email: "john.doe@example.com.au"}, {Id: 2, Email: "John.Doe@example.com"}},
+			Total: 2,
+		})
+		defer svr.Close()
+
+		c := Client{
+			BaseURL:    svr.URL,
+			HTTPClient: &http.Client{},
+		}
+
+		us, err := c.GetUserByEmail(context.Background(), "john.doe@example.com", false)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, us.Id)
 
-		assert.Nil(t, errOrig)
-		assert.Nil(t, errLater)
-		assert.Equal(t, orig, later)
+		_, err = c.GetUserByEmail(context.Background(), "jane.doe@example.com", false)
+		assert.True(t, IsNotFound(err))
 	})
 }
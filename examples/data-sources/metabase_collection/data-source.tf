@@ -0,0 +1,3 @@
+data "metabase_collection" "reports" {
+  path = "Finance/Reports"
+}
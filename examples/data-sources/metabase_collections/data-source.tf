@@ -0,0 +1,4 @@
+data "metabase_collections" "finance" {
+  name_regex = "^Finance"
+  parent_id  = 1
+}
@@ -0,0 +1,2 @@
+# <card_id>
+terraform import metabase_card.monthly_revenue 12
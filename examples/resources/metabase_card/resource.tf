@@ -0,0 +1,21 @@
+resource "metabase_card" "monthly_revenue" {
+  name          = "Monthly revenue"
+  description   = "Revenue per month, all regions"
+  collection_id = metabase_collection.finance.id
+  type          = "model"
+  display       = "line"
+  cache_ttl     = 24
+
+  dataset_query = jsonencode({
+    database = metabase_database.warehouse.database_id
+    type     = "native"
+    native = {
+      query = "SELECT date_trunc('month', created_at) AS month, sum(amount) AS revenue FROM orders GROUP BY 1"
+    }
+  })
+
+  visualization_settings = jsonencode({
+    "graph.dimensions" = ["month"]
+    "graph.metrics"    = ["revenue"]
+  })
+}
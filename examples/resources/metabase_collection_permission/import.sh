@@ -0,0 +1,2 @@
+# <group_id>:<collection_id>
+terraform import metabase_collection_permission.analysts_finance 3:5
@@ -0,0 +1,5 @@
+resource "metabase_collection_permission" "analysts_finance" {
+  collection_id = 5
+  group_id      = metabase_permission_group.analysts.group_id
+  access        = "read"
+}
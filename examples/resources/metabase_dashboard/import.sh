@@ -0,0 +1,2 @@
+# <dashboard_id>
+terraform import metabase_dashboard.sales 4
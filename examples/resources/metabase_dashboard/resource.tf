@@ -0,0 +1,45 @@
+resource "metabase_dashboard" "sales" {
+  name          = "Sales"
+  description   = "Monthly sales overview"
+  collection_id = metabase_collection.finance.id
+
+  parameters = jsonencode([
+    {
+      id   = "region"
+      name = "Region"
+      slug = "region"
+      type = "string/="
+    }
+  ])
+
+  tab {
+    name = "Overview"
+  }
+
+  tab {
+    name = "Details"
+  }
+
+  dashcard {
+    card_id = metabase_card.monthly_revenue.card_id
+    size_x  = 12
+    size_y  = 6
+
+    parameter_mappings = jsonencode([
+      {
+        parameter_id = "region"
+        card_id      = metabase_card.monthly_revenue.card_id
+        target       = ["dimension", ["template-tag", "region"]]
+      }
+    ])
+  }
+
+  dashcard {
+    card_id = metabase_card.orders.card_id
+    tab     = "Details"
+    row     = 0
+    col     = 0
+    size_x  = 24
+    size_y  = 8
+  }
+}
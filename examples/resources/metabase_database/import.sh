@@ -0,0 +1,2 @@
+# <database_id>
+terraform import metabase_database.warehouse 2
@@ -0,0 +1,26 @@
+resource "metabase_database" "warehouse" {
+  name   = "Warehouse"
+  engine = "postgres"
+
+  details = jsonencode({
+    host                        = "warehouse.example.com"
+    port                        = 5432
+    dbname                      = "analytics"
+    user                        = "metabase"
+    ssl                         = true
+    let-user-control-scheduling = true
+  })
+  details_secure = jsonencode({
+    password = var.warehouse_password
+  })
+
+  metadata_sync_schedule {
+    schedule_type   = "hourly"
+    schedule_minute = 15
+  }
+
+  cache_field_values_schedule {
+    schedule_type = "daily"
+    schedule_hour = 2
+  }
+}
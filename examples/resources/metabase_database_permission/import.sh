@@ -0,0 +1,2 @@
+# <group_id>:<database_id>
+terraform import metabase_database_permission.analysts_warehouse 3:2
@@ -0,0 +1,22 @@
+resource "metabase_database_permission" "analysts_warehouse" {
+  group_id    = metabase_permission_group.analysts.group_id
+  database_id = metabase_database.warehouse.database_id
+
+  view_data = "unrestricted"
+
+  schema {
+    name             = "public"
+    create_queries   = "query-builder-and-native"
+    download_results = "full"
+  }
+
+  schema {
+    name = "finance"
+
+    table {
+      table_id         = 42
+      create_queries   = "query-builder"
+      download_results = "limited"
+    }
+  }
+}
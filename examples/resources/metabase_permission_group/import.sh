@@ -0,0 +1,2 @@
+# <group_id>
+terraform import metabase_permission_group.example 3
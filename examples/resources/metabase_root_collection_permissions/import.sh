@@ -0,0 +1,2 @@
+# The root collection permissions are a singleton, imported with the id "root"
+terraform import metabase_root_collection_permissions.our_analytics root
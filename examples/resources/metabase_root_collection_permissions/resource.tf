@@ -0,0 +1,6 @@
+resource "metabase_root_collection_permissions" "our_analytics" {
+  permissions = {
+    "1"                                           = "read"
+    (metabase_permission_group.analysts.group_id) = "write"
+  }
+}
@@ -2,4 +2,11 @@ resource "metabase_user" "example" {
   email      = "john.doe@example.com"
   first_name = "John"
   last_name  = "Doe"
-}
\ No newline at end of file
+  locale     = "en"
+
+  login_attributes = {
+    region = "emea"
+  }
+
+  group_ids = [metabase_permission_group.analysts.group_id]
+}
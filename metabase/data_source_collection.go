@@ -0,0 +1,105 @@
+package metabase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"terraform-provider-metabase/client"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceCollection() *schema.Resource {
+	return &schema.Resource{
+		Description: "Looks up a collection that isn't archived by its `name` or its `path`. Use the `path` when several collections have the same name.",
+
+		ReadContext: dataSourceCollectionRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Description:  "Collection name, which must be unique among the collections that aren't archived",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"name", "path"},
+			},
+			"path": {
+				Description: "Names of the ancestors of the collection and its own, separated by slashes, e.g. `Finance/Reports`",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Computed:    true,
+			},
+			"collection_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"parent_id": {
+				Description: "Id of the parent collection, `0` for the root collection",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+			"location": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"archived": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"personal_owner_id": {
+				Description: "Id of the user owning the personal collection, `0` for the other collections",
+				Type:        schema.TypeInt,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func dataSourceCollectionRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	name := d.Get("name").(string)
+	path := strings.Trim(d.Get("path").(string), "/")
+
+	collections, err := c.GetCollections(ctx)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Error listing collections",
+			Detail:   "Could not list the collections, unexpected error: " + err.Error(),
+		})
+		return diags
+	}
+
+	var found []client.Collection
+	for _, col := range collections {
+		if (path != "" && collections.Path(col) == path) || (path == "" && col.Name == name) {
+			found = append(found, col)
+		}
+	}
+	lookup := fmt.Sprintf("name '%s'", name)
+	if path != "" {
+		lookup = fmt.Sprintf("path '%s'", path)
+	}
+	switch len(found) {
+	case 0:
+		return diag.Errorf("Could not find collection with %s", lookup)
+	case 1:
+	default:
+		return diag.Errorf("Found %d collections with %s, look it up by path instead", len(found), lookup)
+	}
+
+	d.SetId(strconv.Itoa(found[0].Id))
+	for key, value := range flattenCollection(collections, found[0]) {
+		if err := d.Set(key, value); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,49 @@
+package metabase
+
+import (
+	"context"
+	"terraform-provider-metabase/client"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataSourceCollection(t *testing.T) {
+	t.Parallel()
+
+	collections := []client.Collection{
+		{Id: 1, Name: "Finance", Location: "/"},
+		{Id: 5, Name: "Reports", Location: "/1/"},
+		{Id: 6, Name: "Reports", Location: "/7/"},
+		{Id: 7, Name: "Marketing", Location: "/"},
+	}
+
+	t.Run("Look up a collection by path", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/collection": collections})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, dataSourceCollection().Schema, map[string]interface{}{
+			"path": "Marketing/Reports",
+		})
+
+		diags := dataSourceCollectionRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, "6", d.Id())
+		assert.Equal(t, 7, d.Get("parent_id"))
+		assert.Equal(t, "Reports", d.Get("name"))
+	})
+
+	t.Run("Reject an ambiguous name", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/collection": collections})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, dataSourceCollection().Schema, map[string]interface{}{
+			"name": "Reports",
+		})
+
+		diags := dataSourceCollectionRead(context.Background(), d, mockClient(svr))
+
+		assert.True(t, diags.HasError())
+		assert.Equal(t, "Found 2 collections with name 'Reports', look it up by path instead", diags[0].Summary)
+	})
+}
@@ -0,0 +1,143 @@
+package metabase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"terraform-provider-metabase/client"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceCollections() *schema.Resource {
+	return &schema.Resource{
+		Description: "Lists the collections, personal collections included, matching all of the filters.",
+
+		ReadContext: dataSourceCollectionsRead,
+
+		Schema: map[string]*schema.Schema{
+			"name_regex": {
+				Description:  "Only list the collections with a name matching the regular expression",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"parent_id": {
+				Description: "Only list the collections directly in the collection with this id, `0` for the root collection",
+				Type:        schema.TypeInt,
+				Optional:    true,
+			},
+			"archived": {
+				Description: "List the archived collections instead of the other ones. Defaults to `false`",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"collections": {
+				Description: "Collections matching the filters",
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"collection_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"parent_id": {
+							Description: "Id of the parent collection, `0` for the root collection",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+						"location": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"archived": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"personal_owner_id": {
+							Description: "Id of the user owning the personal collection, `0` for the other collections",
+							Type:        schema.TypeInt,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceCollectionsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	nameRegex := d.Get("name_regex").(string)
+	// parent_id 0 is the root collection, only a parent_id left out of the configuration lists every collection
+	parentId := d.Get("parent_id").(int)
+	rawConfig := d.GetRawConfig()
+	filterParent := !rawConfig.IsNull() && !rawConfig.GetAttr("parent_id").IsNull()
+	archived := d.Get("archived").(bool)
+
+	listCollections := c.GetCollections
+	if archived {
+		listCollections = c.GetArchivedCollections
+	}
+	collections, err := listCollections(ctx)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Error listing collections",
+			Detail:   "Could not list the collections, unexpected error: " + err.Error(),
+		})
+		return diags
+	}
+
+	// The regular expression is validated beforehand
+	nameMatcher := regexp.MustCompile(nameRegex)
+	var matching []interface{}
+	for _, col := range collections {
+		if !nameMatcher.MatchString(col.Name) {
+			continue
+		}
+		if filterParent && col.Parent() != parentId {
+			continue
+		}
+		matching = append(matching, flattenCollection(collections, col))
+	}
+
+	d.SetId(fmt.Sprintf("%s:%d:%t", nameRegex, parentId, archived))
+	if err := d.Set("collections", matching); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func flattenCollection(collections client.Collections, col client.Collection) map[string]interface{} {
+	personalOwnerId := 0
+	if col.PersonalOwnerId != nil {
+		personalOwnerId = *col.PersonalOwnerId
+	}
+	return map[string]interface{}{
+		"collection_id":     col.Id,
+		"name":              col.Name,
+		"parent_id":         col.Parent(),
+		"location":          col.Location,
+		"path":              collections.Path(col),
+		"archived":          col.Archived,
+		"personal_owner_id": personalOwnerId,
+	}
+}
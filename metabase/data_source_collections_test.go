@@ -0,0 +1,122 @@
+package metabase
+
+import (
+	"context"
+	"terraform-provider-metabase/client"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-cty/cty/gocty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+// dataSourceDataRaw is like schema.TestResourceDataRaw, with the raw configuration Terraform sends along.
+func dataSourceDataRaw(t *testing.T, r *schema.Resource, raw map[string]interface{}) *schema.ResourceData {
+	t.Helper()
+
+	attributes := map[string]cty.Value{}
+	for name, attributeType := range r.CoreConfigSchema().ImpliedType().AttributeTypes() {
+		v, found := raw[name]
+		if !found {
+			attributes[name] = cty.NullVal(attributeType)
+			continue
+		}
+		value, err := gocty.ToCtyValue(v, attributeType)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		attributes[name] = value
+	}
+
+	diff, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(raw), nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	diff.RawConfig = cty.ObjectVal(attributes)
+	d, err := schema.InternalMap(r.Schema).Data(nil, diff)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return d
+}
+
+func TestDataSourceCollections(t *testing.T) {
+	t.Parallel()
+
+	ownerId := 8
+	collections := []client.Collection{
+		{Id: 1, Name: "Finance", Location: "/"},
+		{Id: 5, Name: "Reports", Location: "/1/"},
+		{Id: 6, Name: "Reports", Location: "/7/"},
+		{Id: 7, Name: "Marketing", Location: "/"},
+		{Id: 9, Name: "John Doe's Personal Collection", Location: "/", PersonalOwnerId: &ownerId},
+	}
+	collectionIds := func(d *schema.ResourceData) []int {
+		var ids []int
+		for _, col := range d.Get("collections").([]interface{}) {
+			ids = append(ids, col.(map[string]interface{})["collection_id"].(int))
+		}
+		return ids
+	}
+
+	t.Run("Filter by name and parent", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/collection": collections})
+		defer svr.Close()
+		d := dataSourceDataRaw(t, dataSourceCollections(), map[string]interface{}{
+			"name_regex": "^Rep",
+			"parent_id":  1,
+		})
+
+		diags := dataSourceCollectionsRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, []interface{}{map[string]interface{}{
+			"collection_id":     5,
+			"name":              "Reports",
+			"parent_id":         1,
+			"location":          "/1/",
+			"path":              "Finance/Reports",
+			"archived":          false,
+			"personal_owner_id": 0,
+		}}, d.Get("collections"))
+	})
+
+	t.Run("Filter on the root collection", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/collection": collections})
+		defer svr.Close()
+		d := dataSourceDataRaw(t, dataSourceCollections(), map[string]interface{}{
+			"parent_id": 0,
+		})
+
+		diags := dataSourceCollectionsRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, []int{1, 7, 9}, collectionIds(d))
+	})
+
+	t.Run("List every collection without parent_id", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/collection": collections})
+		defer svr.Close()
+		d := dataSourceDataRaw(t, dataSourceCollections(), map[string]interface{}{})
+
+		diags := dataSourceCollectionsRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, []int{1, 5, 6, 7, 9}, collectionIds(d))
+	})
+
+	t.Run("List personal collections", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/collection": collections})
+		defer svr.Close()
+		d := dataSourceDataRaw(t, dataSourceCollections(), map[string]interface{}{
+			"name_regex": "Personal",
+		})
+
+		diags := dataSourceCollectionsRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, 8, d.Get("collections.0.personal_owner_id"))
+	})
+}
@@ -2,6 +2,9 @@ package metabase
 
 import (
 	"context"
+	"log"
+	"strconv"
+	"terraform-provider-metabase/client"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
@@ -27,7 +30,33 @@ func dataSourcePermissionGroup() *schema.Resource {
 }
 
 func dataSourcePermissionGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	id := d.Get("name").(string)
-	d.SetId(id)
-	return resourcePermissionGroupRead(ctx, d, meta)
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	name := d.Get("name").(string)
+
+	log.Printf("[INFO] Finding permissionGroup by name '%s'", name)
+	pgs, err := c.GetPermissionGroups(ctx)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Error reading",
+			Detail:   "Could not read permissionGroups: " + err.Error(),
+		})
+		return diags
+	}
+
+	pg, found := findPermissionGroup(pgs, func(pg client.PermissionGroup) bool { return pg.Name == name })
+	if !found {
+		return diag.Errorf("Could not find permission group with name '%s'", name)
+	}
+
+	d.SetId(strconv.Itoa(pg.Id))
+	if err := d.Set("group_id", pg.Id); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
 }
@@ -29,6 +29,32 @@ func dataSourceUser() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"is_active": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"is_superuser": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"login_attributes": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"locale": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"group_ids": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+			},
 		},
 	}
 }
@@ -36,5 +62,9 @@ func dataSourceUser() *schema.Resource {
 func dataSourceUserRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Get("email").(string)
 	d.SetId(id)
-	return resourceUserRead(ctx, d, meta)
+	diags := resourceUserRead(ctx, d, meta)
+	if !diags.HasError() && d.Id() == "" {
+		return diag.Errorf("Could not find user with email '%s'", id)
+	}
+	return diags
 }
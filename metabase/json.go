@@ -0,0 +1,94 @@
+package metabase
+
+import (
+	"encoding/json"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// normalizeJSON re-encodes a JSON document, so that formatting and key order are ignored when comparing it.
+func normalizeJSON(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s, err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return s, err
+	}
+	return string(b), nil
+}
+
+// suppressEquivalentJSON is a DiffSuppressFunc for attributes holding JSON documents, as Metabase doesn't preserve
+// the formatting nor the key order of the documents it stores.
+func suppressEquivalentJSON(_, old, new string, _ *schema.ResourceData) bool {
+	normalizedOld, err := normalizeJSON(old)
+	if err != nil {
+		return false
+	}
+	normalizedNew, err := normalizeJSON(new)
+	if err != nil {
+		return false
+	}
+	return normalizedOld == normalizedNew
+}
+
+// suppressEquivalentQuery is a DiffSuppressFunc for the queries and settings Metabase fills in when saving them, e.g.
+// an empty "template-tags" object in native queries. Besides formatting and key order, null values and empty
+// objects or arrays are ignored when comparing.
+func suppressEquivalentQuery(_, old, new string, _ *schema.ResourceData) bool {
+	normalizedOld, err := normalizeQueryJSON(old)
+	if err != nil {
+		return false
+	}
+	normalizedNew, err := normalizeQueryJSON(new)
+	if err != nil {
+		return false
+	}
+	return normalizedOld == normalizedNew
+}
+
+func normalizeQueryJSON(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s, err
+	}
+	b, err := json.Marshal(dropEmptyJSON(v))
+	if err != nil {
+		return s, err
+	}
+	return string(b), nil
+}
+
+// dropEmptyJSON recursively removes the null values and empty objects or arrays of a decoded JSON document.
+func dropEmptyJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			if e = dropEmptyJSON(e); e != nil {
+				m[k] = e
+			}
+		}
+		if len(m) == 0 {
+			return nil
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			l = append(l, dropEmptyJSON(e))
+		}
+		if len(l) == 0 {
+			return nil
+		}
+		return l
+	}
+	return v
+}
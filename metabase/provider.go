@@ -2,10 +2,14 @@ package metabase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"terraform-provider-metabase/client"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func init() {
@@ -30,12 +34,20 @@ func New(version string) func() *schema.Provider {
 			DataSourcesMap: map[string]*schema.Resource{
 				"metabase_permission_group": dataSourcePermissionGroup(),
 				"metabase_user":             dataSourceUser(),
+				"metabase_collection":       dataSourceCollection(),
+				"metabase_collections":      dataSourceCollections(),
 			},
 			ResourcesMap: map[string]*schema.Resource{
-				"metabase_permission_group": resourcePermissionGroup(),
-				"metabase_user":             resourceUser(),
-				"metabase_membership":       resourceMembership(),
-				"metabase_collection":       resourceCollection(),
+				"metabase_permission_group":            resourcePermissionGroup(),
+				"metabase_user":                        resourceUser(),
+				"metabase_membership":                  resourceMembership(),
+				"metabase_collection":                  resourceCollection(),
+				"metabase_collection_permission":       resourceCollectionPermission(),
+				"metabase_root_collection_permissions": resourceRootCollectionPermissions(),
+				"metabase_database":                    resourceDatabase(),
+				"metabase_database_permission":         resourceDatabasePermission(),
+				"metabase_card":                        resourceCard(),
+				"metabase_dashboard":                   resourceDashboard(),
 			},
 			Schema: map[string]*schema.Schema{
 				"host": {
@@ -45,16 +57,57 @@ func New(version string) func() *schema.Provider {
 				},
 				"username": {
 					Type:        schema.TypeString,
-					Description: "User email of a super admin",
-					Required:    true,
+					Description: "User email of a super admin. Required unless `api_key` is set",
+					Optional:    true,
 					Sensitive:   true,
 				},
 				"password": {
 					Type:        schema.TypeString,
-					Description: "User password",
-					Required:    true,
+					Description: "User password. Required unless `api_key` is set",
+					Optional:    true,
 					Sensitive:   true,
 				},
+				"api_key": {
+					Type:        schema.TypeString,
+					Description: "API key of an admin group, used instead of `username` & `password`. Can also be set with the `METABASE_API_KEY` environment variable",
+					Optional:    true,
+					Sensitive:   true,
+					DefaultFunc: schema.EnvDefaultFunc("METABASE_API_KEY", nil),
+				},
+				"max_retries": {
+					Type:         schema.TypeInt,
+					Description:  "Maximum number of retries of a request failing with a transient error (429, 502, 503, 504 or a network error). Defaults to `4`",
+					Optional:     true,
+					Default:      client.DefaultRetryPolicy.MaxRetries,
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				"retry_wait_min": {
+					Type:         schema.TypeInt,
+					Description:  "Seconds to wait before the first retry, doubled on each following retry. Defaults to `1`",
+					Optional:     true,
+					Default:      int(client.DefaultRetryPolicy.WaitMin / time.Second),
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				"retry_wait_max": {
+					Type:         schema.TypeInt,
+					Description:  "Maximum seconds to wait between retries, including waits requested by a `Retry-After` header. Defaults to `30`",
+					Optional:     true,
+					Default:      int(client.DefaultRetryPolicy.WaitMax / time.Second),
+					ValidateFunc: validation.IntAtLeast(0),
+				},
+				"disable_cache": {
+					Type:        schema.TypeBool,
+					Description: "Always fetch the permission groups and collections listings instead of reusing them during a run. Defaults to `false`",
+					Optional:    true,
+					Default:     false,
+				},
+				"cache_ttl": {
+					Type:         schema.TypeInt,
+					Description:  "Seconds the permission groups and collections listings are reused, `0` to reuse them for the whole run. Changes made by the provider always refresh them. Defaults to `0`",
+					Optional:     true,
+					Default:      0,
+					ValidateFunc: validation.IntAtLeast(0),
+				},
 				"session_id": {
 					Type:        schema.TypeString,
 					Description: "Session ID",
@@ -78,17 +131,18 @@ func configure(version string, p *schema.Provider) func(context.Context, *schema
 		userAgent := p.UserAgent("terraform-provider-metabase", version)
 		username := d.Get("username").(string)
 		password := d.Get("password").(string)
+		apiKey := d.Get("api_key").(string)
 		host := d.Get("host").(string)
 		sessionId := d.Get(sessionIdKey).(string)
 
 		// Warning or errors can be collected in a slice type
 		var diags diag.Diagnostics
 
-		if username == "" || password == "" || host == "" {
+		if host == "" || (apiKey == "" && (username == "" || password == "")) {
 			diags = append(diags, diag.Diagnostic{
 				Severity: diag.Error,
 				Summary:  "Unable to create client",
-				Detail:   "Please provide a valid username, password and host",
+				Detail:   "Please provide a valid host and either an api_key or a username and password",
 			})
 			return nil, diags
 		}
@@ -98,15 +152,61 @@ func configure(version string, p *schema.Provider) func(context.Context, *schema
 			Username:  username,
 			Password:  password,
 			SessionId: sessionId,
+			ApiKey:    apiKey,
 			UserAgent: userAgent,
 		}
 
-		ls, err := client.NewClient(creds)
+		retry := client.RetryPolicy{
+			MaxRetries: d.Get("max_retries").(int),
+			WaitMin:    time.Duration(d.Get("retry_wait_min").(int)) * time.Second,
+			WaitMax:    time.Duration(d.Get("retry_wait_max").(int)) * time.Second,
+		}
+		if retry.WaitMin > retry.WaitMax {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  "Invalid retry configuration",
+				Detail:   "retry_wait_min cannot be greater than retry_wait_max",
+			})
+			return nil, diags
+		}
+
+		ls, err := client.NewClient(ctx, creds)
 		if err != nil {
-			return nil, diag.FromErr(err)
+			return nil, loginDiagnostics(err, host, username)
+		}
+		ls.Client.Retry = retry
+		ls.Client.Cache = client.CachePolicy{
+			Disabled: d.Get("disable_cache").(bool),
+			TTL:      time.Duration(d.Get("cache_ttl").(int)) * time.Second,
 		}
 		_ = d.Set(sessionIdKey, ls.SessionId)
 
 		return ls.Client, diags
 	}
 }
+
+func loginDiagnostics(err error, host string, username string) diag.Diagnostics {
+	var summary, detail string
+	switch {
+	case errors.Is(err, client.ErrHostUnreachable):
+		summary = "Unable to reach Metabase"
+		detail = fmt.Sprintf("Could not connect to '%s', check the host value and that Metabase is running: %s", host, err)
+	case errors.Is(err, client.ErrInvalidCredentials):
+		summary = "Invalid Metabase credentials"
+		detail = fmt.Sprintf("Metabase rejected the username '%s' or its password: %s", username, err)
+	case errors.Is(err, client.ErrLoginRateLimited):
+		summary = "Metabase login rate-limited"
+		detail = "Metabase is throttling login attempts for this user, wait before retrying or use an api_key: " + err.Error()
+	case errors.Is(err, client.ErrPasswordLoginDisabled):
+		summary = "Password login is disabled"
+		detail = fmt.Sprintf("The user '%s' can only sign in through SSO, use an api_key instead: %s", username, err)
+	default:
+		summary = "Unable to create client"
+		detail = "Could not log in to Metabase, unexpected error: " + err.Error()
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Error,
+		Summary:  summary,
+		Detail:   detail,
+	}}
+}
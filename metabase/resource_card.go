@@ -0,0 +1,261 @@
+package metabase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"terraform-provider-metabase/client"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceCard() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceCardCreate,
+		ReadContext:   resourceCardRead,
+		UpdateContext: resourceCardUpdate,
+		DeleteContext: resourceCardDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"card_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"name": {
+				Description: "Card name",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"description": {
+				Description: "Card description",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"collection_id": {
+				Description: "Id of the collection holding the card, the root collection when not set",
+				Type:        schema.TypeInt,
+				Optional:    true,
+			},
+			"type": {
+				Description:  "Card type: `question`, `model` or `metric`",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "question",
+				ValidateFunc: validation.StringInSlice([]string{"question", "model", "metric"}, false),
+			},
+			"display": {
+				Description: "Visualization type, e.g. `table`, `bar`, `line` or `scalar`",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "table",
+			},
+			"dataset_query": {
+				Description:      "Native SQL or MBQL query as JSON, e.g. `jsonencode({ database = 1, type = \"native\", native = { query = \"SELECT 1\" } })`",
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: suppressEquivalentQuery,
+			},
+			"visualization_settings": {
+				Description:      "Visualization settings as JSON",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "{}",
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: suppressEquivalentQuery,
+			},
+			"cache_ttl": {
+				Description:  "Cache TTL multiplier of the query results, the database default when not set",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+		},
+	}
+}
+
+func resourceCardCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	card := expandCard(d)
+
+	created, err := c.CreateCard(ctx, card)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error creating Card '%s'", card.Name),
+			Detail:   "Could not create Card, unexpected error: " + err.Error(),
+		})
+		return diags
+	}
+
+	d.SetId(strconv.Itoa(created.Id))
+	if err := setCard(d, created); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceCardRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	var diags diag.Diagnostics
+
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return diag.Errorf("Invalid card id '%s', it must be a number", d.Id())
+	}
+
+	card, err := c.GetCard(ctx, id)
+	if client.IsNotFound(err) {
+		log.Printf("[WARN] Card with id '%d' not found, removing it from state", id)
+		d.SetId("")
+		return diags
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error reading card with id '%d'", id),
+			Detail:   "Could not read card: " + err.Error(),
+		})
+		return diags
+	}
+	if card.Archived {
+		log.Printf("[WARN] Card with id '%d' is archived, removing it from state", id)
+		d.SetId("")
+		return diags
+	}
+
+	if err := setCard(d, card); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceCardUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	card := expandCard(d)
+	card.Id, _ = strconv.Atoi(d.Id())
+
+	updated, err := c.UpdateCard(ctx, card)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error updating Card '%s'", card.Name),
+			Detail:   "Could not update Card, unexpected error: " + err.Error(),
+		})
+		return diags
+	}
+
+	if err := setCard(d, updated); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceCardDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	id, _ := strconv.Atoi(d.Id())
+
+	err := c.DeleteCard(ctx, id)
+	if err != nil && !client.IsNotFound(err) {
+		return diag.FromErr(err)
+	}
+
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diags
+}
+
+func expandCard(d *schema.ResourceData) client.Card {
+	card := client.Card{
+		Name:                  d.Get("name").(string),
+		Display:               d.Get("display").(string),
+		Type:                  d.Get("type").(string),
+		DatasetQuery:          json.RawMessage(d.Get("dataset_query").(string)),
+		VisualizationSettings: json.RawMessage(d.Get("visualization_settings").(string)),
+	}
+	if description := d.Get("description").(string); description != "" {
+		card.Description = &description
+	}
+	if collectionId := d.Get("collection_id").(int); collectionId != 0 {
+		card.CollectionId = &collectionId
+	}
+	if cacheTTL := d.Get("cache_ttl").(int); cacheTTL != 0 {
+		card.CacheTTL = &cacheTTL
+	}
+	return card
+}
+
+func setCard(d *schema.ResourceData, card client.Card) error {
+	if err := d.Set("card_id", card.Id); err != nil {
+		return err
+	}
+	if err := d.Set("name", card.Name); err != nil {
+		return err
+	}
+	description := ""
+	if card.Description != nil {
+		description = *card.Description
+	}
+	if err := d.Set("description", description); err != nil {
+		return err
+	}
+	collectionId := 0
+	if card.CollectionId != nil {
+		collectionId = *card.CollectionId
+	}
+	if err := d.Set("collection_id", collectionId); err != nil {
+		return err
+	}
+	if card.Type != "" {
+		if err := d.Set("type", card.Type); err != nil {
+			return err
+		}
+	}
+	if err := d.Set("display", card.Display); err != nil {
+		return err
+	}
+	if err := d.Set("dataset_query", string(card.DatasetQuery)); err != nil {
+		return err
+	}
+	visualizationSettings := "{}"
+	if len(card.VisualizationSettings) > 0 && string(card.VisualizationSettings) != "null" {
+		visualizationSettings = string(card.VisualizationSettings)
+	}
+	if err := d.Set("visualization_settings", visualizationSettings); err != nil {
+		return err
+	}
+	cacheTTL := 0
+	if card.CacheTTL != nil {
+		cacheTTL = *card.CacheTTL
+	}
+	if err := d.Set("cache_ttl", cacheTTL); err != nil {
+		return err
+	}
+	return nil
+}
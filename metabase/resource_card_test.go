@@ -0,0 +1,121 @@
+package metabase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"terraform-provider-metabase/client"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceCard(t *testing.T) {
+	t.Parallel()
+
+	query := `{"database": 2, "type": "native", "native": {"query": "SELECT 1"}}`
+	collectionId := 4
+	saved := client.Card{
+		Id:                    7,
+		Name:                  "Revenue",
+		CollectionId:          &collectionId,
+		Display:               "line",
+		DatasetQuery:          json.RawMessage(`{"database":2,"native":{"query":"SELECT 1","template-tags":{}},"type":"native"}`),
+		VisualizationSettings: json.RawMessage(`{}`),
+		Type:                  "model",
+	}
+
+	t.Run("Create card", func(t *testing.T) {
+		var received map[string]interface{}
+		svr := mockServer(map[string]interface{}{
+			"POST /api/card": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				_ = json.NewEncoder(w).Encode(saved)
+			}),
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCard().Schema, map[string]interface{}{
+			"name":          "Revenue",
+			"collection_id": 4,
+			"display":       "line",
+			"type":          "model",
+			"dataset_query": query,
+		})
+
+		diags := resourceCardCreate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "7", d.Id())
+		assert.Equal(t, 7, d.Get("card_id"))
+		assert.Equal(t, "model", received["type"])
+		assert.Equal(t, float64(4), received["collection_id"])
+		assert.Nil(t, received["description"])
+		assert.Nil(t, received["cache_ttl"])
+		assert.Equal(t, map[string]interface{}{"query": "SELECT 1"}, received["dataset_query"].(map[string]interface{})["native"])
+	})
+
+	t.Run("Read card in the root collection", func(t *testing.T) {
+		root := saved
+		root.CollectionId = nil
+		svr := mockServer(map[string]interface{}{
+			"GET /api/card/7": root,
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCard().Schema, map[string]interface{}{})
+		d.SetId("7")
+
+		diags := resourceCardRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, 0, d.Get("collection_id"))
+		assert.Equal(t, "Revenue", d.Get("name"))
+		assert.Equal(t, "{}", d.Get("visualization_settings"))
+	})
+
+	t.Run("Remove card deleted outside of Terraform from state", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCard().Schema, map[string]interface{}{})
+		d.SetId("7")
+
+		diags := resourceCardRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "", d.Id())
+	})
+
+	t.Run("Remove archived card from state", func(t *testing.T) {
+		archived := saved
+		archived.Archived = true
+		svr := mockServer(map[string]interface{}{
+			"GET /api/card/7": archived,
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCard().Schema, map[string]interface{}{})
+		d.SetId("7")
+
+		diags := resourceCardRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "", d.Id())
+	})
+}
+
+func TestSuppressEquivalentQuery(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Ignore defaults filled in by Metabase", func(t *testing.T) {
+		old := `{"database":2,"native":{"query":"SELECT 1","template-tags":{}},"type":"native","parameters":[],"middleware":null}`
+		new := `{"database": 2, "type": "native", "native": {"query": "SELECT 1"}}`
+
+		assert.True(t, suppressEquivalentQuery("dataset_query", old, new, nil))
+	})
+
+	t.Run("Detect query changes", func(t *testing.T) {
+		old := `{"database":2,"native":{"query":"SELECT 1","template-tags":{}},"type":"native"}`
+		new := `{"database": 2, "type": "native", "native": {"query": "SELECT 2"}}`
+
+		assert.False(t, suppressEquivalentQuery("dataset_query", old, new, nil))
+	})
+}
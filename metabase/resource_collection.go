@@ -5,25 +5,41 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
 	"terraform-provider-metabase/client"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	collectionAuthorityOfficial = "official"
+	snippetsNamespace           = "snippets"
+
+	collectionDestroyArchive = "archive"
+	collectionDestroyDelete  = "delete"
+	collectionDestroyAbandon = "abandon"
 )
 
 func resourceCollection() *schema.Resource {
 	return &schema.Resource{
+		Description: "The permissions of snippet folders, in the `snippets` namespace, are not managed: `permissions` and `default_access` can't be set on them.\n\n" +
+			"Changing `parent_id` moves the collection along with its sub-collections, `0` moves it to the root collection. Moving a collection into itself or into one of its sub-collections is rejected at plan time, and the new location is checked after the move. " +
+			"The plan-time check only knows the current collections: a cycle made by several moves of the same plan, e.g. two collections moved into each other, is rejected by Metabase during the apply, once the first move is done.",
+
 		CreateContext: resourceCollectionCreate,
 		ReadContext:   resourceCollectionRead,
 		UpdateContext: resourceCollectionUpdate,
-		DeleteContext: resourceCollectionArchive,
+		DeleteContext: resourceCollectionDelete,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
+		CustomizeDiff: resourceCollectionCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"parent_id": {
-				Description: "Parent collection id",
+				Description: "Parent collection id, `0` for the root collection",
 				Type:        schema.TypeInt,
 				Optional:    true,
 				Computed:    true,
@@ -33,19 +49,50 @@ func resourceCollection() *schema.Resource {
 				Type:        schema.TypeString,
 				Required:    true,
 			},
+			"description": {
+				Description: "Collection description",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"authority_level": {
+				Description:  "`official` to mark the collection as Official, which requires a Metabase Pro or Enterprise plan",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{collectionAuthorityOfficial}, false),
+			},
+			"type": {
+				Description:  "Collection type: `instance-analytics` for the Metabase analytics collection. Regular collections have no type",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"instance-analytics"}, false),
+			},
+			"namespace": {
+				Description:  "`snippets` for a folder of SQL snippets. Regular collections have no namespace",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{snippetsNamespace}, false),
+			},
 			"default_access": {
-				Description: "Default access for all users",
+				Description: "Access of the All Users group (`1`) to the collection: `read`, `write` or `none`. The access of All Users is left unchanged when not set",
 				Type:        schema.TypeString,
-				Default:     "none",
 				Optional:    true,
 			},
 			"permissions": {
-				Type:     schema.TypeMap,
-				Optional: true,
+				Description: "Access of the groups to the collection by group id: `read`, `write` or `none`. Groups not listed are left unchanged and can be granted with `metabase_collection_permission`",
+				Type:        schema.TypeMap,
+				Optional:    true,
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
+			"apply_to_children": {
+				Description: "Also give `default_access` and `permissions` to every sub-collection, nested at any depth, when they change",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
 			"color": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -55,9 +102,82 @@ func resourceCollection() *schema.Resource {
 				Type:     schema.TypeBool,
 				Computed: true,
 			},
+			"on_destroy": {
+				Description:  "What destroying the resource does to the collection: `archive` it, permanently `delete` it, which requires Metabase 50 or later, or `abandon` it in Metabase. Defaults to `archive`",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      collectionDestroyArchive,
+				ValidateFunc: validation.StringInSlice([]string{collectionDestroyArchive, collectionDestroyDelete, collectionDestroyAbandon}, false),
+			},
+			"move_contents_to": {
+				Description: "Id of a collection to move the cards and dashboards of the collection to before it is archived or deleted. Sub-collections are archived or deleted along with their content",
+				Type:        schema.TypeInt,
+				Optional:    true,
+			},
+			"location": {
+				Description: "Ids of the ancestors of the collection, e.g. `/1/5/` for a collection in collection 5 in collection 1",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
+			"path": {
+				Description: "Names of the ancestors of the collection and its own, separated by slashes, e.g. `Finance/Reports`",
+				Type:        schema.TypeString,
+				Computed:    true,
+			},
 		},
 	}
 }
+
+// resourceCollectionCustomizeDiff rejects permissions on snippet folders, and moves of a collection into itself or
+// into one of its sub-collections, whose new location and path are only known after the move. A collection archived
+// outside of Terraform is drift, plan to un-archive it.
+func resourceCollectionCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	// The permissions of snippet folders are in a graph of their own, which isn't managed
+	if d.Get("namespace").(string) == snippetsNamespace {
+		if len(d.Get("permissions").(map[string]interface{})) > 0 || d.Get("default_access").(string) != "" {
+			return fmt.Errorf("permissions and default_access can't be set on a collection of the %s namespace", snippetsNamespace)
+		}
+	}
+	if d.Id() != "" && d.HasChange("parent_id") && d.NewValueKnown("parent_id") {
+		if err := validateCollectionMove(ctx, meta.(*client.Client), d.Id(), d.Get("parent_id").(int)); err != nil {
+			return err
+		}
+		if err := d.SetNewComputed("location"); err != nil {
+			return err
+		}
+		if err := d.SetNewComputed("path"); err != nil {
+			return err
+		}
+	}
+	if d.Get("archived").(bool) {
+		return d.SetNew("archived", false)
+	}
+	return nil
+}
+
+// validateCollectionMove rejects moving a collection into itself or into one of its sub-collections. It is checked
+// against the collections as they are before the apply, so a cycle made by several moves of the same plan, e.g. two
+// collections moved into each other, is only caught by Metabase when the second move is applied.
+func validateCollectionMove(ctx context.Context, c *client.Client, id string, parentId int) error {
+	if parentId == 0 {
+		return nil
+	}
+	collectionId, _ := strconv.Atoi(id)
+	if parentId == collectionId {
+		return fmt.Errorf("collection %d can't be its own parent", collectionId)
+	}
+	collections, err := c.GetCollections(ctx)
+	if err != nil {
+		return err
+	}
+	for _, descendantId := range collections.Descendants(collectionId) {
+		if descendantId == parentId {
+			return fmt.Errorf("collection %d can't be moved into its sub-collection %d", collectionId, parentId)
+		}
+	}
+	return nil
+}
+
 func setRemovedPermissionsToNone(oldPermissions, newPermissions map[string]interface{}) map[string]interface{} {
 	for key := range oldPermissions {
 		if _, ok := newPermissions[key]; !ok {
@@ -66,14 +186,12 @@ func setRemovedPermissionsToNone(oldPermissions, newPermissions map[string]inter
 	}
 	return newPermissions
 }
-func resourceCollectionUpdate(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceCollectionUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
 
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	id, _ := strconv.Atoi(d.Id())
-	parentId := d.Get("parent_id").(int)
 	name := d.Get("name").(string)
 	defaultAccess := d.Get("default_access").(string)
   oldPermission, newPermission := d.GetChange("permissions")
@@ -81,19 +199,11 @@ func resourceCollectionUpdate(_ context.Context, d *schema.ResourceData, meta in
   oldPermissions := oldPermission.(map[string]interface{})
   permissions = setRemovedPermissionsToNone(oldPermissions, permissions)
 
-	color := d.Get("color").(string)
-	archived := d.Get("archived").(bool)
-
-	col := client.Collection{
-		Id:       id,
-		ParentId: parentId,
-		Name:     name,
-		Color:    color,
-		Archived: archived,
-	}
+	col := expandCollection(d)
+	col.Id, _ = strconv.Atoi(d.Id())
 
 	// Update the collection
-	updated, err := c.UpdateCollection(col)
+	updated, err := c.UpdateCollection(ctx, col)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -103,20 +213,58 @@ func resourceCollectionUpdate(_ context.Context, d *schema.ResourceData, meta in
 		return diags
 	}
 
-	collectionGraph := client.CollectionGraph{}
-	if err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  fmt.Sprintf("Error reading the current collection '%s' permissions", name),
-			Detail:   "Could not read the current collection permissions, unexpected error: " + err.Error(),
-		})
+	// Metabase moves the sub-collections along, make sure the collection ended up in its new parent
+	if d.HasChange("parent_id") {
+		parentId := d.Get("parent_id").(int)
+		updated, err = c.GetCollection(ctx, d.Id())
+		if err == nil && parentId == 0 && updated.Location != "/" {
+			err = fmt.Errorf("the collection is in '%s' instead of the root collection", updated.Location)
+		}
+		if err == nil && parentId != 0 && !strings.HasSuffix(updated.Location, fmt.Sprintf("/%d/", parentId)) {
+			err = fmt.Errorf("the collection is in '%s' instead of collection %d", updated.Location, parentId)
+		}
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Error moving collection '%s'", name),
+				Detail:   "Could not verify the new location of the collection: " + err.Error(),
+			})
+			return diags
+		}
+	}
+
+	if err := setCollection(d, updated); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := setCollectionPath(ctx, c, d, updated); err != nil {
+		return diag.FromErr(err)
+	}
+	if updated.Namespace == snippetsNamespace {
 		return diags
 	}
 
-	// Assign the permissions found above
-	collectionGraph.Groups = createCollectionPermissions(permissions, fmt.Sprintf("%d", updated.Id), defaultAccess)
+	// The All Users group is only written when its access changes, or has to be given to the sub-collections
+	allUsersAccess := ""
+	if d.HasChanges("default_access", "apply_to_children") {
+		allUsersAccess = defaultAccess
+	}
+	collectionGraph := client.CollectionGraph{
+		Groups: createCollectionPermissions(permissions, fmt.Sprintf("%d", updated.Id), allUsersAccess),
+	}
 
-	updatedCG, err := c.UpdateCollectionGraph(collectionGraph)
+	// The sub-collections are only written when the permissions change, a rename leaves them untouched
+	if d.Get("apply_to_children").(bool) && d.HasChanges("permissions", "default_access", "apply_to_children") {
+		if err := applyToChildren(ctx, c, collectionGraph.Groups, updated.Id); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Error listing the sub-collections of collection '%s'", name),
+				Detail:   "Could not list the collections, unexpected error: " + err.Error(),
+			})
+			return diags
+		}
+	}
+
+	updatedCG, err := c.UpdateCollectionGraph(ctx, collectionGraph)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -126,51 +274,31 @@ func resourceCollectionUpdate(_ context.Context, d *schema.ResourceData, meta in
 		return diags
 	}
 
-	if updated.ParentId != 0 {
-		if err := d.Set("parent_id", updated.ParentId); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-	if err := d.Set("name", updated.Name); err != nil {
-		return diag.FromErr(err)
-	}
 	if err := d.Set("default_access", defaultAccess); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("permissions", extractCollectionPermissions(updatedCG.Groups, d.Id(), true)); err != nil {
-		return diag.FromErr(err)
-	}
-	if err := d.Set("color", updated.Color); err != nil {
-		return diag.FromErr(err)
-	}
-	if err := d.Set("archived", updated.Archived); err != nil {
+	// Only the configured groups are kept, the others may be granted with metabase_collection_permission
+	updatedPermissions := keepManagedPermissions(extractCollectionPermissions(updatedCG.Groups, d.Id(), true), d.Get("permissions").(map[string]interface{}))
+	if err := d.Set("permissions", updatedPermissions); err != nil {
 		return diag.FromErr(err)
 	}
 
 	return diags
 }
 
-func resourceCollectionCreate(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceCollectionCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
 
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	parentId := d.Get("parent_id").(int)
 	name := d.Get("name").(string)
 	defaultAccess := d.Get("default_access").(string)
 	permissions := d.Get("permissions").(map[string]interface{})
-	color := d.Get("color").(string)
-	archived := d.Get("archived").(bool)
-	col := client.Collection{
-		ParentId: parentId,
-		Name:     name,
-		Color:    color,
-		Archived: archived,
-	}
+	col := expandCollection(d)
 
 	// Create the collection
-	created, err := c.CreateCollection(col)
+	created, err := c.CreateCollection(ctx, col)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -180,21 +308,24 @@ func resourceCollectionCreate(_ context.Context, d *schema.ResourceData, meta in
 		return diags
 	}
 
-	// We need to fetch the current collection graph revision
-	collectionGraph, err := c.GetCollectionGraph()
-	if err != nil {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Error,
-			Summary:  fmt.Sprintf("Error reading the current collection '%s' permissions", name),
-			Detail:   "Could not read the current collection permissions, unexpected error: " + err.Error(),
-		})
+	d.SetId(fmt.Sprint(created.Id))
+	if err := setCollection(d, created); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := setCollectionPath(ctx, c, d, created); err != nil {
+		return diag.FromErr(err)
+	}
+	if created.Namespace == snippetsNamespace {
 		return diags
 	}
 
-	// Assign the permissions found above
-	collectionGraph.Groups = createCollectionPermissions(permissions, fmt.Sprintf("%v", created.Id), defaultAccess)
+	// The client fetches the current graph revision and batches the update with the ones of other collections.
+	// A new collection has no sub-collections yet, so apply_to_children has nothing to do.
+	collectionGraph := client.CollectionGraph{
+		Groups: createCollectionPermissions(permissions, fmt.Sprintf("%v", created.Id), defaultAccess),
+	}
 
-	updated, err := c.UpdateCollectionGraph(collectionGraph)
+	updated, err := c.UpdateCollectionGraph(ctx, collectionGraph)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -204,29 +335,15 @@ func resourceCollectionCreate(_ context.Context, d *schema.ResourceData, meta in
 		return diags
 	}
 
-	d.SetId(fmt.Sprint(created.Id))
-	if col.ParentId != 0 {
-		if err := d.Set("parent_id", created.ParentId); err != nil {
-			return diag.FromErr(err)
-		}
-	}
-	if err := d.Set("name", created.Name); err != nil {
-		return diag.FromErr(err)
-	}
-	if err := d.Set("permissions", extractCollectionPermissions(updated.Groups, d.Id(), false)); err != nil {
-		return diag.FromErr(err)
-	}
-	if err := d.Set("color", created.Color); err != nil {
-		return diag.FromErr(err)
-	}
-	if err := d.Set("archived", created.Archived); err != nil {
+	// Only the configured groups are kept, the others may be granted with metabase_collection_permission
+	if err := d.Set("permissions", keepManagedPermissions(extractCollectionPermissions(updated.Groups, d.Id(), false), permissions)); err != nil {
 		return diag.FromErr(err)
 	}
 
 	return diags
 }
 
-func resourceCollectionRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceCollectionRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
 
 	var diags diag.Diagnostics
@@ -239,7 +356,12 @@ func resourceCollectionRead(_ context.Context, d *schema.ResourceData, meta inte
 
 	log.Printf("[INFO] Finding collection by id '%s'", id)
 
-	col, err := c.GetCollection(id)
+	col, err := c.GetCollection(ctx, id)
+	if client.IsNotFound(err) {
+		log.Printf("[WARN] Collection with id '%s' not found, removing it from state", id)
+		d.SetId("")
+		return diags
+	}
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -249,7 +371,7 @@ func resourceCollectionRead(_ context.Context, d *schema.ResourceData, meta inte
 		return diags
 	}
 
-	cg, err := c.GetCollectionGraph()
+	cg, err := c.GetCollectionGraph(ctx)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -260,44 +382,69 @@ func resourceCollectionRead(_ context.Context, d *schema.ResourceData, meta inte
 	}
 
 	d.SetId(fmt.Sprintf("%v", col.Id))
-	if col.ParentId != 0 {
-		if err := d.Set("parent_id", col.ParentId); err != nil {
-			return diag.FromErr(err)
-		}
+	permissions := extractCollectionPermissions(cg.Groups, fmt.Sprintf("%v", col.Id), true)
+	// Only the groups set in the permissions attribute are managed, the others can be granted with
+	// metabase_collection_permission. Every group is picked up on import, when there is no state yet.
+	if d.Get("name").(string) != "" {
+		permissions = keepManagedPermissions(permissions, d.Get("permissions").(map[string]interface{}))
 	}
-	if err := d.Set("permissions", extractCollectionPermissions(cg.Groups, fmt.Sprintf("%v", col.Id), true)); err != nil {
+	if err := d.Set("permissions", permissions); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("name", col.Name); err != nil {
+	if err := setCollection(d, col); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("color", col.Color); err != nil {
+	if err := setCollectionPath(ctx, c, d, col); err != nil {
 		return diag.FromErr(err)
 	}
-	if err := d.Set("archived", col.Archived); err != nil {
-		return diag.FromErr(err)
+	// Imported collections, and the ones created before on_destroy existed, have no value yet
+	if d.Get("on_destroy").(string) == "" {
+		if err := d.Set("on_destroy", collectionDestroyArchive); err != nil {
+			return diag.FromErr(err)
+		}
 	}
 
 	return diags
 }
 
-// Metabase API does not implement a Delete for collection, the closer action it is to archive it.
-func resourceCollectionArchive(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+// resourceCollectionDelete archives the collection, like the trash of the Metabase UI, unless on_destroy
+// asks to delete it permanently or to leave it in Metabase.
+func resourceCollectionDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
 
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
+	onDestroy := d.Get("on_destroy").(string)
+	if onDestroy == collectionDestroyAbandon {
+		log.Printf("[WARN] Collection with id '%s' is left in Metabase, removing it from state", d.Id())
+		d.SetId("")
+		return diags
+	}
+
 	id, _ := strconv.Atoi(d.Id())
 
-	archived := client.Collection{
-		Id:       id,
-		Name:     d.Get("name").(string),
-		Color:    d.Get("color").(string),
-		Archived: true,
+	if moveTo, ok := d.GetOk("move_contents_to"); ok {
+		if err := moveCollectionItems(ctx, c, id, moveTo.(int)); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Error moving the content of collection id '%s'", d.Id()),
+				Detail:   fmt.Sprintf("Could not move the cards and dashboards to collection %d, unexpected error: %s", moveTo.(int), err.Error()),
+			})
+			return diags
+		}
 	}
 
-	a, err := c.UpdateCollection(archived)
+	// Only archived collections can be deleted
+	archived := expandCollection(d)
+	archived.Id = id
+	archived.Archived = true
+
+	a, err := c.UpdateCollection(ctx, archived)
+	if client.IsNotFound(err) {
+		d.SetId("")
+		return diags
+	}
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -306,11 +453,22 @@ func resourceCollectionArchive(_ context.Context, d *schema.ResourceData, meta i
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  fmt.Sprintf("Error archived collection id '%s'", d.Id()),
-			Detail:   "It was not possible to archive (delete) the collection: " + err.Error(),
+			Detail:   "It was not possible to archive (delete) the collection, Metabase left it unarchived",
 		})
 		return diags
 	}
 
+	if onDestroy == collectionDestroyDelete {
+		if err := c.DeleteCollection(ctx, id); err != nil && !client.IsNotFound(err) {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Error deleting collection id '%s'", d.Id()),
+				Detail:   "Could not delete the archived collection, unexpected error: " + err.Error(),
+			})
+			return diags
+		}
+	}
+
 	// d.SetId("") is automatically called assuming delete returns no errors, but
 	// it is added here for explicitness.
 	d.SetId("")
@@ -318,6 +476,90 @@ func resourceCollectionArchive(_ context.Context, d *schema.ResourceData, meta i
 	return diags
 }
 
+// moveCollectionItems moves the cards and dashboards directly in the collection to another one.
+func moveCollectionItems(ctx context.Context, c *client.Client, collectionId, moveTo int) error {
+	items, err := c.GetCollectionItems(ctx, collectionId)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := c.MoveCollectionItem(ctx, item, moveTo); err != nil {
+			return fmt.Errorf("%s '%s': %w", item.Model, item.Name, err)
+		}
+	}
+	return nil
+}
+
+func expandCollection(d *schema.ResourceData) client.Collection {
+	col := client.Collection{
+		Name:      d.Get("name").(string),
+		Color:     d.Get("color").(string),
+		Archived:  d.Get("archived").(bool),
+		Type:      d.Get("type").(string),
+		Namespace: d.Get("namespace").(string),
+	}
+	if parentId := d.Get("parent_id").(int); parentId != 0 {
+		col.ParentId = &parentId
+	}
+	if description := d.Get("description").(string); description != "" {
+		col.Description = &description
+	}
+	if authorityLevel := d.Get("authority_level").(string); authorityLevel != "" {
+		col.AuthorityLevel = &authorityLevel
+	}
+	return col
+}
+
+func setCollection(d *schema.ResourceData, col client.Collection) error {
+	// The parent is taken from the location, so that a move to the root collection is detected as well
+	if err := d.Set("parent_id", col.Parent()); err != nil {
+		return err
+	}
+	if err := d.Set("name", col.Name); err != nil {
+		return err
+	}
+	description := ""
+	if col.Description != nil {
+		description = *col.Description
+	}
+	if err := d.Set("description", description); err != nil {
+		return err
+	}
+	authorityLevel := ""
+	if col.AuthorityLevel != nil {
+		authorityLevel = *col.AuthorityLevel
+	}
+	if err := d.Set("authority_level", authorityLevel); err != nil {
+		return err
+	}
+	if err := d.Set("type", col.Type); err != nil {
+		return err
+	}
+	if err := d.Set("namespace", col.Namespace); err != nil {
+		return err
+	}
+	if err := d.Set("color", col.Color); err != nil {
+		return err
+	}
+	if err := d.Set("location", col.Location); err != nil {
+		return err
+	}
+	return d.Set("archived", col.Archived)
+}
+
+// setCollectionPath sets the path from the names of the ancestors, found in the collections listing.
+func setCollectionPath(ctx context.Context, c *client.Client, d *schema.ResourceData, col client.Collection) error {
+	path := col.Name
+	if col.Location != "" && col.Location != "/" {
+		collections, err := c.GetCollections(ctx)
+		if err != nil {
+			return err
+		}
+		path = collections.Path(col)
+	}
+	return d.Set("path", path)
+}
+
 func extractCollectionPermissions(cgGroups map[string]map[string]string, collectionId string, skipNone bool) map[string]string {
 	permissions := make(map[string]string)
 	for groupId := range cgGroups {
@@ -344,6 +586,18 @@ func extractCollectionPermissions(cgGroups map[string]map[string]string, collect
 	return permissions
 }
 
+func keepManagedPermissions(permissions map[string]string, managed map[string]interface{}) map[string]string {
+	kept := make(map[string]string)
+	for groupId, access := range permissions {
+		if _, found := managed[groupId]; found {
+			kept[groupId] = access
+		}
+	}
+	return kept
+}
+
+// createCollectionPermissions builds the graph cells of the collection. The All Users group is left out when
+// defaultAccess is empty.
 func createCollectionPermissions(p map[string]interface{}, collectionId string, defaultAccess string) map[string]map[string]string {
 	permissions := make(map[string]map[string]string)
 	for groupId, access := range p {
@@ -354,8 +608,28 @@ func createCollectionPermissions(p map[string]interface{}, collectionId string,
 		permissions[groupId] = map[string]string{}
 		permissions[groupId][collectionId] = access.(string)
 	}
-	permissions["1"] = map[string]string{}
-	permissions["1"][collectionId] = defaultAccess
+	if defaultAccess != "" {
+		permissions["1"] = map[string]string{}
+		permissions["1"][collectionId] = defaultAccess
+	}
 
 	return permissions
 }
+
+// applyToChildren copies the access of each group to the collection onto all of its sub-collections,
+// so that they are written in the same collection graph update.
+func applyToChildren(ctx context.Context, c *client.Client, groups map[string]map[string]string, collectionId int) error {
+	collections, err := c.GetCollections(ctx)
+	if err != nil {
+		return err
+	}
+	id := strconv.Itoa(collectionId)
+	for _, childId := range collections.Descendants(collectionId) {
+		for _, permissions := range groups {
+			if access, found := permissions[id]; found {
+				permissions[strconv.Itoa(childId)] = access
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package metabase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"terraform-provider-metabase/client"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const noCollectionAccess = "none"
+
+var collectionAccessLevels = []string{"read", "write"}
+
+func resourceCollectionPermission() *schema.Resource {
+	return &schema.Resource{
+		Description: "Grants a group access to a collection, leaving the access of the other groups untouched. Don't use it for a group also listed in the `permissions` of a `metabase_collection`, nor for the All Users group of a `metabase_collection` which sets `default_access`.\n\n" +
+			"With `apply_to_children`, the sub-collections existing when the access is written get the same access, in a single update of the collection graph. Sub-collections created later inherit the access of their parent from Metabase.",
+
+		CreateContext: resourceCollectionPermissionCreate,
+		ReadContext:   resourceCollectionPermissionRead,
+		UpdateContext: resourceCollectionPermissionUpdate,
+		DeleteContext: resourceCollectionPermissionDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceCollectionPermissionImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"collection_id": {
+				Description: "Collection id",
+				Type:        schema.TypeInt,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"group_id": {
+				Description:  "Permission group id. The Administrators group (`2`) always has write access to every collection",
+				Type:         schema.TypeInt,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntNotInSlice([]int{administratorsGroupId}),
+			},
+			"access": {
+				Description:  "Access of the group to the collection: `read` or `write`",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(collectionAccessLevels, false),
+			},
+			"apply_to_children": {
+				Description: "Also give the access to every sub-collection, nested at any depth. Only the access to the collection itself is read back",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+		},
+	}
+}
+
+func resourceCollectionPermissionCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	groupId := d.Get("group_id").(int)
+	collectionId := d.Get("collection_id").(int)
+
+	if diags := updateCollectionPermission(ctx, d, meta, d.Get("access").(string)); diags.HasError() {
+		return diags
+	}
+
+	d.SetId(fmt.Sprintf("%d:%d", groupId, collectionId))
+	return diag.Diagnostics{}
+}
+
+func resourceCollectionPermissionUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return updateCollectionPermission(ctx, d, meta, d.Get("access").(string))
+}
+
+// updateCollectionPermission only writes the cell of the group and collection in the collection graph,
+// and the cells of the sub-collections with apply_to_children.
+func updateCollectionPermission(ctx context.Context, d *schema.ResourceData, meta interface{}, access string) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	groupId := strconv.Itoa(d.Get("group_id").(int))
+	collectionId := strconv.Itoa(d.Get("collection_id").(int))
+
+	cg := client.CollectionGraph{
+		Groups: map[string]map[string]string{groupId: {collectionId: access}},
+	}
+	if d.Get("apply_to_children").(bool) {
+		if err := applyToChildren(ctx, c, cg.Groups, d.Get("collection_id").(int)); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Error listing the sub-collections of collection '%s'", collectionId),
+				Detail:   "Could not list the collections, unexpected error: " + err.Error(),
+			})
+			return diags
+		}
+	}
+	updated, err := c.UpdateCollectionGraph(ctx, cg)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error updating group '%s' permissions on collection '%s'", groupId, collectionId),
+			Detail:   "Could not update the collection permissions, unexpected error: " + err.Error(),
+		})
+		return diags
+	}
+
+	if access != noCollectionAccess {
+		if err := d.Set("access", updated.Groups[groupId][collectionId]); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	return diags
+}
+
+func resourceCollectionPermissionRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	var diags diag.Diagnostics
+
+	groupId := strconv.Itoa(d.Get("group_id").(int))
+	collectionId := strconv.Itoa(d.Get("collection_id").(int))
+
+	cg, err := c.GetCollectionGraph(ctx)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error reading group '%s' permissions on collection '%s'", groupId, collectionId),
+			Detail:   "Could not read the collection permissions, unexpected error: " + err.Error(),
+		})
+		return diags
+	}
+
+	// The cell is missing when the group or the collection was deleted
+	access, found := cg.Groups[groupId][collectionId]
+	if !found || access == noCollectionAccess {
+		log.Printf("[WARN] Group '%s' has no access to collection '%s', removing the permission from state", groupId, collectionId)
+		d.SetId("")
+		return diags
+	}
+
+	if err := d.Set("access", access); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+// Removing the resource revokes the group's access to the collection.
+func resourceCollectionPermissionDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := updateCollectionPermission(ctx, d, meta, noCollectionAccess); diags.HasError() {
+		return diags
+	}
+
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func resourceCollectionPermissionImport(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.Split(d.Id(), ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid import id '%s', expected '<group_id>:<collection_id>'", d.Id())
+	}
+	groupId, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid group id '%s': %w", parts[0], err)
+	}
+	collectionId, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid collection id '%s': %w", parts[1], err)
+	}
+	if err := d.Set("group_id", groupId); err != nil {
+		return nil, err
+	}
+	if err := d.Set("collection_id", collectionId); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,158 @@
+package metabase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"terraform-provider-metabase/client"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceCollectionPermission(t *testing.T) {
+	t.Parallel()
+
+	cg := client.CollectionGraph{
+		Revision: 4,
+		Groups: map[string]map[string]string{
+			"1": {"5": "write", "6": "none"},
+			"3": {"5": "read", "6": "none"},
+		},
+	}
+
+	t.Run("Grant writes only the cell of the group and collection", func(t *testing.T) {
+		var received client.CollectionGraph
+		svr := mockServer(map[string]interface{}{
+			"GET /api/collection/graph": cg,
+			"PUT /api/collection/graph": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				_ = json.NewEncoder(w).Encode(client.CollectionGraph{
+					Revision: 5,
+					Groups:   map[string]map[string]string{"1": {"5": "write", "6": "none"}, "3": {"5": "read", "6": "write"}},
+				})
+			}),
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCollectionPermission().Schema, map[string]interface{}{
+			"collection_id": 6,
+			"group_id":      3,
+			"access":        "write",
+		})
+
+		diags := resourceCollectionPermissionCreate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, "3:6", d.Id())
+		assert.Equal(t, client.CollectionGraph{Revision: 4, Groups: map[string]map[string]string{"3": {"6": "write"}}}, received)
+		assert.Equal(t, "write", d.Get("access"))
+	})
+
+	t.Run("Grant to sub-collections in the same update", func(t *testing.T) {
+		puts := 0
+		var received client.CollectionGraph
+		svr := mockServer(map[string]interface{}{
+			"GET /api/collection": []map[string]interface{}{
+				{"id": "root", "name": "Our analytics"},
+				{"id": 5, "name": "Finance", "location": "/"},
+				{"id": 6, "name": "Reports", "location": "/5/"},
+				{"id": 7, "name": "Monthly", "location": "/5/6/"},
+				{"id": 8, "name": "Marketing", "location": "/"},
+			},
+			"GET /api/collection/graph": cg,
+			"PUT /api/collection/graph": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				puts++
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				_ = json.NewEncoder(w).Encode(received)
+			}),
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCollectionPermission().Schema, map[string]interface{}{
+			"collection_id":     5,
+			"group_id":          3,
+			"access":            "write",
+			"apply_to_children": true,
+		})
+
+		diags := resourceCollectionPermissionCreate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, 1, puts)
+		assert.Equal(t, map[string]map[string]string{"3": {"5": "write", "6": "write", "7": "write"}}, received.Groups)
+	})
+
+	t.Run("Read access", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/collection/graph": cg})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCollectionPermission().Schema, map[string]interface{}{})
+		d.SetId("3:5")
+		_, err := resourceCollectionPermissionImport(context.Background(), d, nil)
+		assert.Nil(t, err)
+
+		diags := resourceCollectionPermissionRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "3:5", d.Id())
+		assert.Equal(t, "read", d.Get("access"))
+	})
+
+	t.Run("Remove revoked permission from state", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/collection/graph": cg})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCollectionPermission().Schema, map[string]interface{}{
+			"collection_id": 6,
+			"group_id":      3,
+			"access":        "write",
+		})
+		d.SetId("3:6")
+
+		diags := resourceCollectionPermissionRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "", d.Id())
+	})
+
+	t.Run("Delete revokes the access", func(t *testing.T) {
+		var received client.CollectionGraph
+		svr := mockServer(map[string]interface{}{
+			"GET /api/collection/graph": cg,
+			"PUT /api/collection/graph": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				_ = json.NewEncoder(w).Encode(cg)
+			}),
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCollectionPermission().Schema, map[string]interface{}{
+			"collection_id": 5,
+			"group_id":      3,
+			"access":        "read",
+		})
+		d.SetId("3:5")
+
+		diags := resourceCollectionPermissionDelete(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "", d.Id())
+		assert.Equal(t, map[string]map[string]string{"3": {"5": "none"}}, received.Groups)
+	})
+
+	t.Run("Reject invalid import id", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, resourceCollectionPermission().Schema, map[string]interface{}{})
+		d.SetId("5")
+
+		_, err := resourceCollectionPermissionImport(context.Background(), d, nil)
+
+		assert.ErrorContains(t, err, "expected '<group_id>:<collection_id>'")
+	})
+	t.Run("Reject the Administrators group", func(t *testing.T) {
+		diags := resourceCollectionPermission().Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+			"collection_id": 5,
+			"group_id":      2,
+			"access":        "read",
+		}))
+
+		assert.True(t, diags.HasError())
+	})
+}
@@ -0,0 +1,535 @@
+package metabase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"terraform-provider-metabase/client"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceCollectionRead(t *testing.T) {
+	t.Parallel()
+
+	cg := client.CollectionGraph{
+		Revision: 1,
+		Groups: map[string]map[string]string{
+			"1": {"5": "none"},
+			"3": {"5": "write"},
+		},
+	}
+
+	t.Run("Read existing collection", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{
+			"GET /api/collection/5":     client.Collection{Id: 5, Name: "Finance", Color: "#31698A"},
+			"GET /api/collection/graph": cg,
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCollection().Schema, map[string]interface{}{})
+		d.SetId("5")
+
+		diags := resourceCollectionRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "5", d.Id())
+		assert.Equal(t, "Finance", d.Get("name"))
+		assert.Equal(t, map[string]interface{}{"3": "write"}, d.Get("permissions"))
+	})
+
+	t.Run("Ignore groups granted outside of the permissions attribute", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{
+			"GET /api/collection/5": client.Collection{Id: 5, Name: "Finance", Color: "#31698A"},
+			"GET /api/collection/graph": client.CollectionGraph{
+				Revision: 1,
+				Groups: map[string]map[string]string{
+					"3": {"5": "read"},
+					"4": {"5": "write"},
+				},
+			},
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCollection().Schema, map[string]interface{}{
+			"name":        "Finance",
+			"permissions": map[string]interface{}{"3": "write"},
+		})
+		d.SetId("5")
+
+		diags := resourceCollectionRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, map[string]interface{}{"3": "read"}, d.Get("permissions"))
+	})
+
+	t.Run("Remove collection deleted out-of-band from state", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/collection/graph": cg})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCollection().Schema, map[string]interface{}{})
+		d.SetId("5")
+
+		diags := resourceCollectionRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "", d.Id())
+	})
+}
+
+func TestResourceCollectionArchivedDrift(t *testing.T) {
+	t.Parallel()
+
+	state := &terraform.InstanceState{
+		ID: "5",
+		Attributes: map[string]string{
+			"id":             "5",
+			"name":           "Finance",
+			"color":          "#31698A",
+			"default_access": "none",
+			"archived":       "true",
+		},
+	}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name": "Finance",
+	})
+
+	diff, err := resourceCollection().Diff(context.Background(), state, config, nil)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, diff)
+	assert.Equal(t, "false", diff.Attributes["archived"].New)
+	assert.False(t, diff.RequiresNew())
+}
+
+func TestResourceCollectionCreate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Create official collection", func(t *testing.T) {
+		var received map[string]interface{}
+		description := "Curated reports"
+		official := "official"
+		svr := mockServer(map[string]interface{}{
+			"POST /api/collection": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				_ = json.NewEncoder(w).Encode(client.Collection{Id: 5, Name: "Finance", Description: &description, AuthorityLevel: &official, Color: "#31698A"})
+			}),
+			"GET /api/collection/graph": client.CollectionGraph{Revision: 1},
+			"PUT /api/collection/graph": client.CollectionGraph{Revision: 2, Groups: map[string]map[string]string{"1": {"5": "none"}}},
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCollection().Schema, map[string]interface{}{
+			"name":            "Finance",
+			"description":     "Curated reports",
+			"authority_level": "official",
+		})
+
+		diags := resourceCollectionCreate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, "5", d.Id())
+		assert.Equal(t, "Curated reports", received["description"])
+		assert.Equal(t, "official", received["authority_level"])
+		assert.NotContains(t, received, "namespace")
+		assert.Equal(t, "official", d.Get("authority_level"))
+	})
+
+	t.Run("Leave All Users untouched without default_access", func(t *testing.T) {
+		var received client.CollectionGraph
+		svr := mockServer(map[string]interface{}{
+			"POST /api/collection":      client.Collection{Id: 5, Name: "Finance", Color: "#31698A"},
+			"GET /api/collection/graph": client.CollectionGraph{Revision: 1},
+			"PUT /api/collection/graph": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				_ = json.NewEncoder(w).Encode(received)
+			}),
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCollection().Schema, map[string]interface{}{
+			"name":        "Finance",
+			"permissions": map[string]interface{}{"3": "read"},
+		})
+
+		diags := resourceCollectionCreate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, map[string]map[string]string{"3": {"5": "read"}}, received.Groups)
+	})
+
+	t.Run("Keep only the configured groups in state", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{
+			"POST /api/collection":      client.Collection{Id: 5, Name: "Finance", Color: "#31698A"},
+			"GET /api/collection/graph": client.CollectionGraph{Revision: 1},
+			"PUT /api/collection/graph": client.CollectionGraph{Revision: 2, Groups: map[string]map[string]string{
+				"3": {"5": "write"},
+				"4": {"5": "read"},
+				"6": {"5": "none"},
+			}},
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCollection().Schema, map[string]interface{}{
+			"name":        "Finance",
+			"permissions": map[string]interface{}{"3": "write"},
+		})
+
+		diags := resourceCollectionCreate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, map[string]interface{}{"3": "write"}, d.Get("permissions"))
+	})
+
+	t.Run("Snippet folders leave the collection graph untouched", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{
+			"POST /api/collection": client.Collection{Id: 6, Name: "Snippets", Namespace: "snippets", Color: "#31698A"},
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCollection().Schema, map[string]interface{}{
+			"name":      "Snippets",
+			"namespace": "snippets",
+		})
+
+		diags := resourceCollectionCreate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, "6", d.Id())
+		assert.Equal(t, "snippets", d.Get("namespace"))
+	})
+
+	t.Run("Reject permissions on snippet folders", func(t *testing.T) {
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name":        "Snippets",
+			"namespace":   "snippets",
+			"permissions": map[string]interface{}{"3": "read"},
+		})
+
+		_, err := resourceCollection().Diff(context.Background(), nil, config, nil)
+
+		assert.ErrorContains(t, err, "snippets namespace")
+	})
+}
+
+func TestResourceCollectionUpdate(t *testing.T) {
+	t.Parallel()
+
+	state := &terraform.InstanceState{
+		ID: "5",
+		Attributes: map[string]string{
+			"id":                "5",
+			"name":              "Finance",
+			"color":             "#31698A",
+			"default_access":    "read",
+			"permissions.%":     "1",
+			"permissions.3":     "write",
+			"apply_to_children": "false",
+		},
+	}
+
+	t.Run("Only write All Users when default_access changes", func(t *testing.T) {
+		var received client.CollectionGraph
+		svr := mockServer(map[string]interface{}{
+			"PUT /api/collection/5":     client.Collection{Id: 5, Name: "Sales", Color: "#31698A", Location: "/"},
+			"GET /api/collection/graph": client.CollectionGraph{Revision: 1},
+			"PUT /api/collection/graph": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				_ = json.NewEncoder(w).Encode(received)
+			}),
+		})
+		defer svr.Close()
+		r := resourceCollection()
+		diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name":           "Sales",
+			"default_access": "read",
+			"permissions":    map[string]interface{}{"3": "write"},
+		}), mockClient(svr))
+		assert.Nil(t, err)
+		d, err := schema.InternalMap(r.Schema).Data(state, diff)
+		assert.Nil(t, err)
+
+		diags := resourceCollectionUpdate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, map[string]map[string]string{"3": {"5": "write"}}, received.Groups)
+		assert.Equal(t, "read", d.Get("default_access"))
+	})
+
+	t.Run("Keep only the configured groups in state", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{
+			"PUT /api/collection/5":     client.Collection{Id: 5, Name: "Sales", Color: "#31698A", Location: "/"},
+			"GET /api/collection/graph": client.CollectionGraph{Revision: 1},
+			"PUT /api/collection/graph": client.CollectionGraph{Revision: 2, Groups: map[string]map[string]string{
+				"3": {"5": "write"},
+				"4": {"5": "read"},
+			}},
+		})
+		defer svr.Close()
+		r := resourceCollection()
+		diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name":           "Sales",
+			"default_access": "read",
+			"permissions":    map[string]interface{}{"3": "write"},
+		}), mockClient(svr))
+		assert.Nil(t, err)
+		d, err := schema.InternalMap(r.Schema).Data(state, diff)
+		assert.Nil(t, err)
+
+		diags := resourceCollectionUpdate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, map[string]interface{}{"3": "write"}, d.Get("permissions"))
+	})
+
+	t.Run("Leave the sub-collections untouched on rename", func(t *testing.T) {
+		var received client.CollectionGraph
+		svr := mockServer(map[string]interface{}{
+			"GET /api/collection": []client.Collection{
+				{Id: 5, Name: "Finance", Location: "/"},
+				{Id: 6, Name: "Reports", Location: "/5/"},
+			},
+			"PUT /api/collection/5":     client.Collection{Id: 5, Name: "Sales", Color: "#31698A", Location: "/"},
+			"GET /api/collection/graph": client.CollectionGraph{Revision: 1},
+			"PUT /api/collection/graph": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				_ = json.NewEncoder(w).Encode(received)
+			}),
+		})
+		defer svr.Close()
+		children := state.DeepCopy()
+		children.Attributes["apply_to_children"] = "true"
+		r := resourceCollection()
+		diff, err := r.Diff(context.Background(), children, terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name":              "Sales",
+			"default_access":    "read",
+			"permissions":       map[string]interface{}{"3": "write"},
+			"apply_to_children": true,
+		}), mockClient(svr))
+		assert.Nil(t, err)
+		d, err := schema.InternalMap(r.Schema).Data(children, diff)
+		assert.Nil(t, err)
+
+		diags := resourceCollectionUpdate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, map[string]map[string]string{"3": {"5": "write"}}, received.Groups)
+	})
+}
+
+func TestResourceCollectionMove(t *testing.T) {
+	t.Parallel()
+
+	collections := []client.Collection{
+		{Id: 1, Name: "Finance", Location: "/"},
+		{Id: 5, Name: "Reports", Location: "/1/"},
+		{Id: 6, Name: "Monthly", Location: "/1/5/"},
+		{Id: 7, Name: "Marketing", Location: "/"},
+	}
+	finance, marketing := 1, 7
+	state := &terraform.InstanceState{
+		ID: "5",
+		Attributes: map[string]string{
+			"id":             "5",
+			"parent_id":      "1",
+			"name":           "Reports",
+			"color":          "#31698A",
+			"default_access": "none",
+			"location":       "/1/",
+			"path":           "Finance/Reports",
+		},
+	}
+	moveTo := func(parentId int) *terraform.ResourceConfig {
+		return terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name":      "Reports",
+			"parent_id": parentId,
+		})
+	}
+
+	t.Run("Reject moving a collection into its sub-collection", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/collection": collections})
+		defer svr.Close()
+
+		_, err := resourceCollection().Diff(context.Background(), state, moveTo(6), mockClient(svr))
+
+		assert.ErrorContains(t, err, "can't be moved into its sub-collection 6")
+	})
+
+	t.Run("Reject moving a collection into itself", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{})
+		defer svr.Close()
+
+		_, err := resourceCollection().Diff(context.Background(), state, moveTo(5), mockClient(svr))
+
+		assert.ErrorContains(t, err, "own parent")
+	})
+
+	t.Run("Verify the new location after a move", func(t *testing.T) {
+		moved := client.Collection{Id: 5, ParentId: &marketing, Name: "Reports", Color: "#31698A", Location: "/7/"}
+		svr := mockServer(map[string]interface{}{
+			"GET /api/collection":       collections,
+			"PUT /api/collection/5":     moved,
+			"GET /api/collection/5":     moved,
+			"GET /api/collection/graph": client.CollectionGraph{Revision: 1},
+			"PUT /api/collection/graph": client.CollectionGraph{Revision: 2},
+		})
+		defer svr.Close()
+		r := resourceCollection()
+		diff, err := r.Diff(context.Background(), state, moveTo(7), mockClient(svr))
+		assert.Nil(t, err)
+		d, err := schema.InternalMap(r.Schema).Data(state, diff)
+		assert.Nil(t, err)
+
+		diags := resourceCollectionUpdate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, "/7/", d.Get("location"))
+		assert.Equal(t, "Marketing/Reports", d.Get("path"))
+	})
+
+	t.Run("Move a collection to the root collection", func(t *testing.T) {
+		var received map[string]interface{}
+		moved := client.Collection{Id: 5, Name: "Reports", Color: "#31698A", Location: "/"}
+		svr := mockServer(map[string]interface{}{
+			"GET /api/collection": collections,
+			"PUT /api/collection/5": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				_ = json.NewEncoder(w).Encode(moved)
+			}),
+			"GET /api/collection/5":     moved,
+			"GET /api/collection/graph": client.CollectionGraph{Revision: 1},
+			"PUT /api/collection/graph": client.CollectionGraph{Revision: 2},
+		})
+		defer svr.Close()
+		r := resourceCollection()
+		diff, err := r.Diff(context.Background(), state, moveTo(0), mockClient(svr))
+		assert.Nil(t, err)
+		d, err := schema.InternalMap(r.Schema).Data(state, diff)
+		assert.Nil(t, err)
+
+		diags := resourceCollectionUpdate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Contains(t, received, "parent_id")
+		assert.Nil(t, received["parent_id"])
+		assert.Equal(t, 0, d.Get("parent_id"))
+		assert.Equal(t, "/", d.Get("location"))
+		assert.Equal(t, "Reports", d.Get("path"))
+
+		// A move to the root collection made outside of Terraform is drift
+		read, err := schema.InternalMap(r.Schema).Data(state, nil)
+		assert.Nil(t, err)
+
+		diags = resourceCollectionRead(context.Background(), read, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, 0, read.Get("parent_id"))
+	})
+
+	t.Run("Fail when the collection didn't move to the root collection", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{
+			"GET /api/collection":   collections,
+			"PUT /api/collection/5": client.Collection{Id: 5, Name: "Reports", Location: "/"},
+			"GET /api/collection/5": client.Collection{Id: 5, ParentId: &finance, Name: "Reports", Location: "/1/"},
+		})
+		defer svr.Close()
+		r := resourceCollection()
+		diff, err := r.Diff(context.Background(), state, moveTo(0), mockClient(svr))
+		assert.Nil(t, err)
+		d, err := schema.InternalMap(r.Schema).Data(state, diff)
+		assert.Nil(t, err)
+
+		diags := resourceCollectionUpdate(context.Background(), d, mockClient(svr))
+
+		assert.True(t, diags.HasError())
+		assert.Contains(t, diags[0].Detail, "instead of the root collection")
+	})
+
+	t.Run("Fail when the collection didn't move", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{
+			"GET /api/collection":   collections,
+			"PUT /api/collection/5": client.Collection{Id: 5, ParentId: &marketing, Name: "Reports", Location: "/7/"},
+			"GET /api/collection/5": client.Collection{Id: 5, ParentId: &finance, Name: "Reports", Location: "/1/"},
+		})
+		defer svr.Close()
+		r := resourceCollection()
+		diff, err := r.Diff(context.Background(), state, moveTo(7), mockClient(svr))
+		assert.Nil(t, err)
+		d, err := schema.InternalMap(r.Schema).Data(state, diff)
+		assert.Nil(t, err)
+
+		diags := resourceCollectionUpdate(context.Background(), d, mockClient(svr))
+
+		assert.True(t, diags.HasError())
+		assert.Equal(t, "Error moving collection 'Reports'", diags[0].Summary)
+	})
+}
+
+func TestResourceCollectionDelete(t *testing.T) {
+	t.Parallel()
+
+	attributes := map[string]interface{}{
+		"name":             "Sandbox",
+		"on_destroy":       "delete",
+		"move_contents_to": 9,
+	}
+
+	t.Run("Move the content then delete the collection", func(t *testing.T) {
+		var moved []string
+		deleted := false
+		move := func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]int
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, 9, body["collection_id"])
+			moved = append(moved, r.URL.Path)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{})
+		}
+		svr := mockServer(map[string]interface{}{
+			"GET /api/collection/5/items": map[string]interface{}{
+				"data": []client.CollectionItem{{Id: 3, Model: "dataset", Name: "Orders"}, {Id: 4, Model: "dashboard", Name: "Sales"}},
+			},
+			"PUT /api/card/3":       http.HandlerFunc(move),
+			"PUT /api/dashboard/4":  http.HandlerFunc(move),
+			"PUT /api/collection/5": client.Collection{Id: 5, Name: "Sandbox", Archived: true},
+			"DELETE /api/collection/5": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				deleted = true
+				w.WriteHeader(http.StatusNoContent)
+			}),
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCollection().Schema, attributes)
+		d.SetId("5")
+
+		diags := resourceCollectionDelete(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, []string{"/api/card/3", "/api/dashboard/4"}, moved)
+		assert.True(t, deleted)
+		assert.Equal(t, "", d.Id())
+	})
+
+	t.Run("Abandon the collection", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCollection().Schema, map[string]interface{}{
+			"name":       "Sandbox",
+			"on_destroy": "abandon",
+		})
+		d.SetId("5")
+
+		diags := resourceCollectionDelete(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, "", d.Id())
+	})
+
+	t.Run("Fail when Metabase doesn't archive the collection", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{
+			"PUT /api/collection/5": client.Collection{Id: 5, Name: "Sandbox"},
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceCollection().Schema, map[string]interface{}{"name": "Sandbox"})
+		d.SetId("5")
+
+		diags := resourceCollectionDelete(context.Background(), d, mockClient(svr))
+
+		assert.True(t, diags.HasError())
+		assert.Equal(t, "5", d.Id())
+	})
+}
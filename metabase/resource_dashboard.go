@@ -0,0 +1,499 @@
+package metabase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"terraform-provider-metabase/client"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceDashboard() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDashboardCreate,
+		ReadContext:   resourceDashboardRead,
+		UpdateContext: resourceDashboardUpdate,
+		DeleteContext: resourceDashboardDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"dashboard_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"name": {
+				Description: "Dashboard name",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"description": {
+				Description: "Dashboard description",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"collection_id": {
+				Description: "Id of the collection holding the dashboard, the root collection when not set",
+				Type:        schema.TypeInt,
+				Optional:    true,
+			},
+			"parameters": {
+				Description:      "Dashboard filters as a JSON array",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "[]",
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: suppressEquivalentQuery,
+			},
+			"tab": {
+				Description: "Dashboard tabs, in display order",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"tab_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"name": {
+							Description: "Tab name, unique within the dashboard",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+					},
+				},
+			},
+			"dashcard": {
+				Description: "Cards shown on the dashboard",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"dashcard_id": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"card_id": {
+							Description: "Id of the card shown, not set for text and heading cards",
+							Type:        schema.TypeInt,
+							Optional:    true,
+						},
+						"tab": {
+							Description: "Name of the tab showing the card, the first tab when not set",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"row": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+						"col": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+						"size_x": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+						"size_y": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+						},
+						"parameter_mappings": {
+							Description:      "Mappings of the dashboard parameters to the card fields as a JSON array",
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          "[]",
+							ValidateFunc:     validation.StringIsJSON,
+							DiffSuppressFunc: suppressEquivalentQuery,
+						},
+						"visualization_settings": {
+							Description:      "Visualization settings overriding the card ones as JSON",
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          "{}",
+							ValidateFunc:     validation.StringIsJSON,
+							DiffSuppressFunc: suppressEquivalentQuery,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceDashboardCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	dashboard, err := expandDashboard(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	created, err := c.CreateDashboard(ctx, dashboard)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error creating Dashboard '%s'", dashboard.Name),
+			Detail:   "Could not create Dashboard, unexpected error: " + err.Error(),
+		})
+		return diags
+	}
+	d.SetId(strconv.Itoa(created.Id))
+
+	// Tabs and dashcards can only be added to an existing dashboard
+	dashboard.Id = created.Id
+	updated, err := c.UpdateDashboard(ctx, dashboard)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error adding the cards of Dashboard '%s'", dashboard.Name),
+			Detail:   "Could not update Dashboard, unexpected error: " + err.Error(),
+		})
+		return diags
+	}
+
+	if err := setDashboard(d, updated, dashboard.Dashcards); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceDashboardRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	var diags diag.Diagnostics
+
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return diag.Errorf("Invalid dashboard id '%s', it must be a number", d.Id())
+	}
+
+	dashboard, err := c.GetDashboard(ctx, id)
+	if client.IsNotFound(err) {
+		log.Printf("[WARN] Dashboard with id '%d' not found, removing it from state", id)
+		d.SetId("")
+		return diags
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error reading dashboard with id '%d'", id),
+			Detail:   "Could not read dashboard: " + err.Error(),
+		})
+		return diags
+	}
+	if dashboard.Archived {
+		log.Printf("[WARN] Dashboard with id '%d' is archived, removing it from state", id)
+		d.SetId("")
+		return diags
+	}
+
+	current, _ := dashcardsFromList(d.Get("dashcard").([]interface{}))
+	if err := setDashboard(d, dashboard, current); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceDashboardUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	dashboard, err := expandDashboard(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	updated, err := c.UpdateDashboard(ctx, dashboard)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error updating Dashboard '%s'", dashboard.Name),
+			Detail:   "Could not update Dashboard, unexpected error: " + err.Error(),
+		})
+		return diags
+	}
+
+	if err := setDashboard(d, updated, dashboard.Dashcards); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceDashboardDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	id, _ := strconv.Atoi(d.Id())
+
+	err := c.DeleteDashboard(ctx, id)
+	if err != nil && !client.IsNotFound(err) {
+		return diag.FromErr(err)
+	}
+
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diags
+}
+
+// expandDashboard builds the whole dashboard as sent to the bulk update. Existing tabs and dashcards keep their id so
+// that Metabase updates them in place, new ones get a negative id.
+func expandDashboard(d *schema.ResourceData) (client.Dashboard, error) {
+	dashboard := client.Dashboard{
+		Name:       d.Get("name").(string),
+		Parameters: json.RawMessage(d.Get("parameters").(string)),
+	}
+	if d.Id() != "" {
+		dashboard.Id, _ = strconv.Atoi(d.Id())
+	}
+	if description := d.Get("description").(string); description != "" {
+		dashboard.Description = &description
+	}
+	if collectionId := d.Get("collection_id").(int); collectionId != 0 {
+		dashboard.CollectionId = &collectionId
+	}
+
+	oldTabs, newTabs := d.GetChange("tab")
+	tabIds := map[string]int{}
+	for _, t := range oldTabs.([]interface{}) {
+		tab := t.(map[string]interface{})
+		tabIds[tab["name"].(string)] = tab["tab_id"].(int)
+	}
+	dashboard.Tabs = []client.DashboardTab{}
+	for i, t := range newTabs.([]interface{}) {
+		name := t.(map[string]interface{})["name"].(string)
+		id, ok := tabIds[name]
+		if !ok || id <= 0 {
+			id = -(i + 1)
+		}
+		for _, tab := range dashboard.Tabs {
+			if tab.Name == name {
+				return dashboard, fmt.Errorf("duplicate dashboard tab '%s'", name)
+			}
+		}
+		dashboard.Tabs = append(dashboard.Tabs, client.DashboardTab{Id: id, Name: name, Position: i})
+	}
+
+	oldDashcards, newDashcards := d.GetChange("dashcard")
+	previous, _ := dashcardsFromList(oldDashcards.([]interface{}))
+	dashcards, tabs := dashcardsFromList(newDashcards.([]interface{}))
+	reused := map[int]bool{}
+	for i := range dashcards {
+		dashcards[i].Id = -(i + 1)
+		// Keep the dashcard showing the same card, the dashboard filters and click behaviour set in Metabase stay
+		// attached to it.
+		for _, p := range previous {
+			if p.Id > 0 && !reused[p.Id] && sameCardId(p.CardId, dashcards[i].CardId) {
+				dashcards[i].Id = p.Id
+				reused[p.Id] = true
+				break
+			}
+		}
+
+		if len(dashboard.Tabs) == 0 {
+			if tabs[i] != "" {
+				return dashboard, fmt.Errorf("dashcard %d is on tab '%s' but the dashboard has no tabs", i, tabs[i])
+			}
+			continue
+		}
+		tabId := dashboard.Tabs[0].Id
+		if tabs[i] != "" {
+			found := false
+			for _, tab := range dashboard.Tabs {
+				if tab.Name == tabs[i] {
+					tabId, found = tab.Id, true
+				}
+			}
+			if !found {
+				return dashboard, fmt.Errorf("dashcard %d is on tab '%s' which is not a tab of the dashboard", i, tabs[i])
+			}
+		}
+		dashcards[i].DashboardTabId = &tabId
+	}
+	dashboard.Dashcards = dashcards
+
+	return dashboard, nil
+}
+
+// dashcardsFromList reads the dashcard blocks, along with the name of their tab.
+func dashcardsFromList(list []interface{}) ([]client.Dashcard, []string) {
+	dashcards := make([]client.Dashcard, 0, len(list))
+	tabs := make([]string, 0, len(list))
+	for _, e := range list {
+		m := e.(map[string]interface{})
+		dashcard := client.Dashcard{
+			Id:                    m["dashcard_id"].(int),
+			Row:                   m["row"].(int),
+			Col:                   m["col"].(int),
+			SizeX:                 m["size_x"].(int),
+			SizeY:                 m["size_y"].(int),
+			ParameterMappings:     json.RawMessage(m["parameter_mappings"].(string)),
+			VisualizationSettings: json.RawMessage(m["visualization_settings"].(string)),
+		}
+		if cardId := m["card_id"].(int); cardId != 0 {
+			dashcard.CardId = &cardId
+		}
+		dashcards = append(dashcards, dashcard)
+		tabs = append(tabs, m["tab"].(string))
+	}
+	return dashcards, tabs
+}
+
+func sameCardId(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// orderDashcards sorts the dashcards returned by Metabase in the order of the configured ones, matched by id or, for
+// the ones just created, by card and position. Dashcards added outside of Terraform come last. The index of the
+// matching configured dashcard is returned along, -1 when none.
+func orderDashcards(configured, actual []client.Dashcard) ([]client.Dashcard, []int) {
+	used := make([]bool, len(actual))
+	ordered := make([]client.Dashcard, 0, len(actual))
+	indexes := make([]int, 0, len(actual))
+	for i, c := range configured {
+		for j, a := range actual {
+			if used[j] {
+				continue
+			}
+			if (c.Id > 0 && c.Id == a.Id) || (c.Id <= 0 && sameCardId(c.CardId, a.CardId) &&
+				c.Row == a.Row && c.Col == a.Col && c.SizeX == a.SizeX && c.SizeY == a.SizeY) {
+				used[j] = true
+				ordered = append(ordered, a)
+				indexes = append(indexes, i)
+				break
+			}
+		}
+	}
+
+	var others []client.Dashcard
+	for j, a := range actual {
+		if !used[j] {
+			others = append(others, a)
+		}
+	}
+	sort.SliceStable(others, func(i, j int) bool {
+		if others[i].Row != others[j].Row {
+			return others[i].Row < others[j].Row
+		}
+		return others[i].Col < others[j].Col
+	})
+	for _, a := range others {
+		ordered = append(ordered, a)
+		indexes = append(indexes, -1)
+	}
+	return ordered, indexes
+}
+
+func jsonOrDefault(raw json.RawMessage, defaultValue string) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return defaultValue
+	}
+	return string(raw)
+}
+
+func setDashboard(d *schema.ResourceData, dashboard client.Dashboard, configured []client.Dashcard) error {
+	if err := d.Set("dashboard_id", dashboard.Id); err != nil {
+		return err
+	}
+	if err := d.Set("name", dashboard.Name); err != nil {
+		return err
+	}
+	description := ""
+	if dashboard.Description != nil {
+		description = *dashboard.Description
+	}
+	if err := d.Set("description", description); err != nil {
+		return err
+	}
+	collectionId := 0
+	if dashboard.CollectionId != nil {
+		collectionId = *dashboard.CollectionId
+	}
+	if err := d.Set("collection_id", collectionId); err != nil {
+		return err
+	}
+	if err := d.Set("parameters", jsonOrDefault(dashboard.Parameters, "[]")); err != nil {
+		return err
+	}
+
+	sort.SliceStable(dashboard.Tabs, func(i, j int) bool {
+		return dashboard.Tabs[i].Position < dashboard.Tabs[j].Position
+	})
+	tabs := make([]interface{}, 0, len(dashboard.Tabs))
+	tabNames := map[int]string{}
+	for _, tab := range dashboard.Tabs {
+		tabs = append(tabs, map[string]interface{}{
+			"tab_id": tab.Id,
+			"name":   tab.Name,
+		})
+		tabNames[tab.Id] = tab.Name
+	}
+	if err := d.Set("tab", tabs); err != nil {
+		return err
+	}
+
+	_, configuredTabs := dashcardsFromList(d.Get("dashcard").([]interface{}))
+	ordered, indexes := orderDashcards(configured, dashboard.Dashcards)
+	dashcards := make([]interface{}, 0, len(ordered))
+	for i, dashcard := range ordered {
+		cardId := 0
+		if dashcard.CardId != nil {
+			cardId = *dashcard.CardId
+		}
+		tab := ""
+		if dashcard.DashboardTabId != nil {
+			tab = tabNames[*dashcard.DashboardTabId]
+		}
+		// Dashcards configured without a tab are on the first one
+		if len(dashboard.Tabs) > 0 && tab == dashboard.Tabs[0].Name &&
+			indexes[i] >= 0 && indexes[i] < len(configuredTabs) && configuredTabs[indexes[i]] == "" {
+			tab = ""
+		}
+		dashcards = append(dashcards, map[string]interface{}{
+			"dashcard_id":            dashcard.Id,
+			"card_id":                cardId,
+			"tab":                    tab,
+			"row":                    dashcard.Row,
+			"col":                    dashcard.Col,
+			"size_x":                 dashcard.SizeX,
+			"size_y":                 dashcard.SizeY,
+			"parameter_mappings":     jsonOrDefault(dashcard.ParameterMappings, "[]"),
+			"visualization_settings": jsonOrDefault(dashcard.VisualizationSettings, "{}"),
+		})
+	}
+	if err := d.Set("dashcard", dashcards); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package metabase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"terraform-provider-metabase/client"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// dashboardMock stores the dashboard sent by the bulk update and returns it with ids assigned the way Metabase does.
+func dashboardMock(dashboard *client.Dashboard) map[string]interface{} {
+	update := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var received client.Dashboard
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		tabIds := map[int]int{}
+		for i, tab := range received.Tabs {
+			if tab.Id < 0 {
+				tabIds[tab.Id] = 100 - tab.Id
+				received.Tabs[i].Id = 100 - tab.Id
+			}
+		}
+		for i, dashcard := range received.Dashcards {
+			if dashcard.Id < 0 {
+				received.Dashcards[i].Id = 200 - dashcard.Id
+			}
+			if dashcard.DashboardTabId != nil && *dashcard.DashboardTabId < 0 {
+				received.Dashcards[i].DashboardTabId = intPtr(tabIds[*dashcard.DashboardTabId])
+			}
+		}
+		received.Id = 1
+		*dashboard = received
+		_ = json.NewEncoder(w).Encode(received)
+	})
+	return map[string]interface{}{
+		"POST /api/dashboard": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(client.Dashboard{Id: 1, Name: "Sales"})
+		}),
+		"PUT /api/dashboard/1": update,
+	}
+}
+
+func TestResourceDashboard(t *testing.T) {
+	t.Parallel()
+
+	config := map[string]interface{}{
+		"name": "Sales",
+		"tab": []interface{}{
+			map[string]interface{}{"name": "Overview"},
+			map[string]interface{}{"name": "Details"},
+		},
+		"dashcard": []interface{}{
+			map[string]interface{}{"card_id": 3, "row": 4, "size_x": 12, "size_y": 6},
+			map[string]interface{}{"card_id": 4, "tab": "Details", "size_x": 6, "size_y": 4},
+			map[string]interface{}{"card_id": 5, "row": 0, "size_x": 6, "size_y": 4},
+		},
+	}
+
+	t.Run("Create dashboard with tabs and dashcards", func(t *testing.T) {
+		var saved client.Dashboard
+		svr := mockServer(dashboardMock(&saved))
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceDashboard().Schema, config)
+
+		diags := resourceDashboardCreate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "1", d.Id())
+		assert.Equal(t, []client.DashboardTab{{Id: 101, Name: "Overview", Position: 0}, {Id: 102, Name: "Details", Position: 1}}, saved.Tabs)
+		assert.Equal(t, 101, *saved.Dashcards[0].DashboardTabId)
+		assert.Equal(t, 102, *saved.Dashcards[1].DashboardTabId)
+		assert.Equal(t, 101, d.Get("tab.0.tab_id"))
+		// Dashcards stay in the configured order and keep an unset tab
+		assert.Equal(t, 201, d.Get("dashcard.0.dashcard_id"))
+		assert.Equal(t, "", d.Get("dashcard.0.tab"))
+		assert.Equal(t, "Details", d.Get("dashcard.1.tab"))
+		assert.Equal(t, 5, d.Get("dashcard.2.card_id"))
+	})
+
+	t.Run("Reject dashcard on unknown tab", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, resourceDashboard().Schema, map[string]interface{}{
+			"name": "Sales",
+			"tab":  []interface{}{map[string]interface{}{"name": "Overview"}},
+			"dashcard": []interface{}{
+				map[string]interface{}{"card_id": 3, "tab": "Typo", "size_x": 6, "size_y": 4},
+			},
+		})
+
+		_, err := expandDashboard(d)
+
+		assert.ErrorContains(t, err, "'Typo' which is not a tab")
+	})
+
+	t.Run("Update dashboard keeps existing dashcards", func(t *testing.T) {
+		state := &terraform.InstanceState{
+			ID: "1",
+			Attributes: map[string]string{
+				"id":                                "1",
+				"dashboard_id":                      "1",
+				"name":                              "Sales",
+				"parameters":                        "[]",
+				"tab.#":                             "0",
+				"dashcard.#":                        "2",
+				"dashcard.0.dashcard_id":            "7",
+				"dashcard.0.card_id":                "3",
+				"dashcard.0.tab":                    "",
+				"dashcard.0.row":                    "0",
+				"dashcard.0.col":                    "0",
+				"dashcard.0.size_x":                 "6",
+				"dashcard.0.size_y":                 "4",
+				"dashcard.0.parameter_mappings":     "[]",
+				"dashcard.0.visualization_settings": "{}",
+				"dashcard.1.dashcard_id":            "8",
+				"dashcard.1.card_id":                "4",
+				"dashcard.1.tab":                    "",
+				"dashcard.1.row":                    "0",
+				"dashcard.1.col":                    "6",
+				"dashcard.1.size_x":                 "6",
+				"dashcard.1.size_y":                 "4",
+				"dashcard.1.parameter_mappings":     "[]",
+				"dashcard.1.visualization_settings": "{}",
+			},
+		}
+		// The first card is removed, the second one moved and a new one added
+		raw := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name": "Sales",
+			"dashcard": []interface{}{
+				map[string]interface{}{"card_id": 4, "size_x": 6, "size_y": 4},
+				map[string]interface{}{"card_id": 5, "row": 4, "size_x": 12, "size_y": 4},
+			},
+		})
+		r := resourceDashboard()
+		diff, err := r.Diff(context.Background(), state, raw, nil)
+		assert.Nil(t, err)
+		d, err := schema.InternalMap(r.Schema).Data(state, diff)
+		assert.Nil(t, err)
+
+		var saved client.Dashboard
+		svr := mockServer(dashboardMock(&saved))
+		defer svr.Close()
+
+		diags := resourceDashboardUpdate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Len(t, saved.Dashcards, 2)
+		assert.Equal(t, 8, saved.Dashcards[0].Id)
+		assert.Equal(t, 0, saved.Dashcards[0].Col)
+		assert.Equal(t, 202, saved.Dashcards[1].Id)
+		assert.Equal(t, 8, d.Get("dashcard.0.dashcard_id"))
+		assert.Equal(t, 202, d.Get("dashcard.1.dashcard_id"))
+	})
+
+	t.Run("Read dashboard in the configured order", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{
+			"GET /api/dashboard/1": client.Dashboard{
+				Id:   1,
+				Name: "Sales",
+				Dashcards: []client.Dashcard{
+					{Id: 9, CardId: intPtr(6), Row: 8, SizeX: 6, SizeY: 4},
+					{Id: 8, CardId: intPtr(4), SizeX: 6, SizeY: 4},
+					{Id: 7, CardId: intPtr(3), Row: 4, SizeX: 6, SizeY: 4},
+				},
+			},
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceDashboard().Schema, map[string]interface{}{
+			"name": "Sales",
+			"dashcard": []interface{}{
+				map[string]interface{}{"card_id": 3, "size_x": 6, "size_y": 4},
+				map[string]interface{}{"card_id": 4, "size_x": 6, "size_y": 4},
+			},
+		})
+		d.SetId("1")
+		_ = d.Set("dashcard", []interface{}{
+			map[string]interface{}{"dashcard_id": 7, "card_id": 3, "size_x": 6, "size_y": 4, "parameter_mappings": "[]", "visualization_settings": "{}"},
+			map[string]interface{}{"dashcard_id": 8, "card_id": 4, "size_x": 6, "size_y": 4, "parameter_mappings": "[]", "visualization_settings": "{}"},
+		})
+
+		diags := resourceDashboardRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, 7, d.Get("dashcard.0.dashcard_id"))
+		assert.Equal(t, 4, d.Get("dashcard.0.row"))
+		assert.Equal(t, 8, d.Get("dashcard.1.dashcard_id"))
+		// Dashcards added outside of Terraform come last
+		assert.Equal(t, 9, d.Get("dashcard.2.dashcard_id"))
+		assert.Equal(t, "[]", d.Get("parameters"))
+	})
+
+	t.Run("Remove dashboard deleted outside of Terraform from state", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceDashboard().Schema, map[string]interface{}{})
+		d.SetId("1")
+
+		diags := resourceDashboardRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "", d.Id())
+	})
+}
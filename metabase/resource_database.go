@@ -0,0 +1,376 @@
+package metabase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"terraform-provider-metabase/client"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceDatabase() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDatabaseCreate,
+		ReadContext:   resourceDatabaseRead,
+		UpdateContext: resourceDatabaseUpdate,
+		DeleteContext: resourceDatabaseDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"database_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"name": {
+				Description: "Database display name",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"engine": {
+				Description: "Database engine, e.g. `postgres`, `bigquery-cloud-sdk` or `snowflake`",
+				Type:        schema.TypeString,
+				Required:    true,
+			},
+			"details": {
+				Description:      "Connection details as JSON, e.g. host, port & dbname. Only the keys set here are compared with the details returned by Metabase",
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: suppressEquivalentJSON,
+			},
+			"details_secure": {
+				Description:      "Sensitive connection details as JSON, e.g. password or service-account-json, merged into `details`. Metabase never returns these values so changes made outside of Terraform are not detected. The secrets stored in Metabase and not set here are kept",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: suppressEquivalentJSON,
+			},
+			"metadata_sync_schedule": {
+				Description: "When to sync the database schema. Requires `let-user-control-scheduling` in `details`",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem:        databaseScheduleSchema(),
+			},
+			"cache_field_values_schedule": {
+				Description: "When to scan the database for filter values. Requires `let-user-control-scheduling` in `details`",
+				Type:        schema.TypeList,
+				Optional:    true,
+				Computed:    true,
+				MaxItems:    1,
+				Elem:        databaseScheduleSchema(),
+			},
+			"auto_run_queries": {
+				Description: "Automatically run queries when doing simple filtering and summarizing",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+			},
+			"is_full_sync": {
+				Description: "Scan the database for filter values, set to `false` to only scan on demand",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+			},
+		},
+	}
+}
+
+func databaseScheduleSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"schedule_type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{"hourly", "daily", "weekly", "monthly"}, false),
+			},
+			"schedule_day": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}, false),
+			},
+			"schedule_frame": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"first", "mid", "last"}, false),
+			},
+			"schedule_hour": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(0, 23),
+			},
+			"schedule_minute": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(0, 59),
+			},
+		},
+	}
+}
+
+func resourceDatabaseCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	db, err := expandDatabase(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	created, err := c.CreateDatabase(ctx, db)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error creating Database '%s'", db.Name),
+			Detail:   "Could not create Database, unexpected error: " + err.Error(),
+		})
+		return diags
+	}
+
+	d.SetId(strconv.Itoa(created.Id))
+	if err := setDatabase(d, created); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceDatabaseRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	var diags diag.Diagnostics
+
+	id, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return diag.Errorf("Invalid database id '%s', it must be a number", d.Id())
+	}
+
+	db, err := c.GetDatabase(ctx, id)
+	if client.IsNotFound(err) {
+		log.Printf("[WARN] Database with id '%d' not found, removing it from state", id)
+		d.SetId("")
+		return diags
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error reading database with id '%d'", id),
+			Detail:   "Could not read database: " + err.Error(),
+		})
+		return diags
+	}
+
+	if err := setDatabase(d, db); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceDatabaseUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	db, err := expandDatabase(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	db.Id, _ = strconv.Atoi(d.Id())
+
+	// Metabase replaces the details with the ones sent, and only keeps a stored secret when its redacted value is
+	// sent back. Secrets not managed in details_secure, e.g. on an imported database, must not be erased.
+	current, err := c.GetDatabase(ctx, db.Id)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error updating Database '%s'", db.Name),
+			Detail:   "Could not read the current Database details, unexpected error: " + err.Error(),
+		})
+		return diags
+	}
+	for k, v := range current.Details {
+		if _, found := db.Details[k]; !found && v == client.RedactedValue {
+			db.Details[k] = client.RedactedValue
+		}
+	}
+
+	updated, err := c.UpdateDatabase(ctx, db)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error updating Database '%s'", db.Name),
+			Detail:   "Could not update Database, unexpected error: " + err.Error(),
+		})
+		return diags
+	}
+
+	if err := setDatabase(d, updated); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceDatabaseDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	id, _ := strconv.Atoi(d.Id())
+
+	err := c.DeleteDatabase(ctx, id)
+	if err != nil && !client.IsNotFound(err) {
+		return diag.FromErr(err)
+	}
+
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diags
+}
+
+func expandDatabase(d *schema.ResourceData) (client.Database, error) {
+	details := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(d.Get("details").(string)), &details); err != nil {
+		return client.Database{}, fmt.Errorf("details is not a JSON object: %w", err)
+	}
+	if secure := d.Get("details_secure").(string); secure != "" {
+		secureDetails := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(secure), &secureDetails); err != nil {
+			return client.Database{}, errors.New("details_secure is not a JSON object")
+		}
+		for k, v := range secureDetails {
+			details[k] = v
+		}
+	}
+
+	db := client.Database{
+		Name:           d.Get("name").(string),
+		Engine:         d.Get("engine").(string),
+		Details:        details,
+		AutoRunQueries: d.Get("auto_run_queries").(bool),
+		IsFullSync:     d.Get("is_full_sync").(bool),
+	}
+	metadataSync := expandDatabaseSchedule(d.Get("metadata_sync_schedule").([]interface{}))
+	cacheFieldValues := expandDatabaseSchedule(d.Get("cache_field_values_schedule").([]interface{}))
+	if metadataSync != nil || cacheFieldValues != nil {
+		db.Schedules = &client.DatabaseSchedules{
+			MetadataSync:     metadataSync,
+			CacheFieldValues: cacheFieldValues,
+		}
+	}
+	return db, nil
+}
+
+func expandDatabaseSchedule(l []interface{}) *client.Schedule {
+	if len(l) == 0 || l[0] == nil {
+		return nil
+	}
+	s := l[0].(map[string]interface{})
+	return &client.Schedule{
+		ScheduleType:   s["schedule_type"].(string),
+		ScheduleDay:    s["schedule_day"].(string),
+		ScheduleFrame:  s["schedule_frame"].(string),
+		ScheduleHour:   s["schedule_hour"].(int),
+		ScheduleMinute: s["schedule_minute"].(int),
+	}
+}
+
+func flattenDatabaseSchedule(s *client.Schedule) []interface{} {
+	if s == nil {
+		return nil
+	}
+	return []interface{}{map[string]interface{}{
+		"schedule_type":   s.ScheduleType,
+		"schedule_day":    s.ScheduleDay,
+		"schedule_frame":  s.ScheduleFrame,
+		"schedule_hour":   s.ScheduleHour,
+		"schedule_minute": s.ScheduleMinute,
+	}}
+}
+
+// flattenDatabaseDetails returns the details from Metabase to store in state. The sensitive details are left out, as
+// Metabase redacts them, and so are the details that are not configured, as Metabase adds defaults for the engine.
+// All the details are kept when nothing is configured yet, e.g. on import.
+func flattenDatabaseDetails(details map[string]interface{}, configured string, secure string) (string, error) {
+	configuredDetails := map[string]interface{}{}
+	if configured != "" {
+		if err := json.Unmarshal([]byte(configured), &configuredDetails); err != nil {
+			return "", err
+		}
+	}
+	secureDetails := map[string]interface{}{}
+	if secure != "" {
+		if err := json.Unmarshal([]byte(secure), &secureDetails); err != nil {
+			return "", err
+		}
+	}
+
+	flattened := map[string]interface{}{}
+	for k, v := range details {
+		if _, found := secureDetails[k]; found || v == client.RedactedValue {
+			continue
+		}
+		if _, found := configuredDetails[k]; configured != "" && !found {
+			continue
+		}
+		flattened[k] = v
+	}
+	b, err := json.Marshal(flattened)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func setDatabase(d *schema.ResourceData, db client.Database) error {
+	details, err := flattenDatabaseDetails(db.Details, d.Get("details").(string), d.Get("details_secure").(string))
+	if err != nil {
+		return err
+	}
+
+	if err := d.Set("database_id", db.Id); err != nil {
+		return err
+	}
+	if err := d.Set("name", db.Name); err != nil {
+		return err
+	}
+	if err := d.Set("engine", db.Engine); err != nil {
+		return err
+	}
+	if err := d.Set("details", details); err != nil {
+		return err
+	}
+	if db.Schedules != nil {
+		if err := d.Set("metadata_sync_schedule", flattenDatabaseSchedule(db.Schedules.MetadataSync)); err != nil {
+			return err
+		}
+		if err := d.Set("cache_field_values_schedule", flattenDatabaseSchedule(db.Schedules.CacheFieldValues)); err != nil {
+			return err
+		}
+	}
+	if err := d.Set("auto_run_queries", db.AutoRunQueries); err != nil {
+		return err
+	}
+	if err := d.Set("is_full_sync", db.IsFullSync); err != nil {
+		return err
+	}
+	return nil
+}
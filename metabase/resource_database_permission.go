@@ -0,0 +1,410 @@
+package metabase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"terraform-provider-metabase/client"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	noCreateQueries = "no"
+	noDownload      = "none"
+)
+
+var (
+	viewDataLevels      = []string{"unrestricted", "blocked", "legacy-no-self-service"}
+	createQueriesLevels = []string{"query-builder-and-native", "query-builder", noCreateQueries}
+	downloadLevels      = []string{"full", "limited", noDownload}
+)
+
+func resourceDatabasePermission() *schema.Resource {
+	return &schema.Resource{
+		Description: "Manages the grants of a group on a database: the queries it can create and the results it can download, on the whole database or by schema. " +
+			"Removing the resource resets `create_queries` to `no` and `download_results` to `none`, and leaves the access of the group to the data, `view_data`, unchanged.\n\n" +
+			"`view_data` is read back as an empty string when the access of the group is not the same on the whole database, e.g. for sandboxed tables or an access granted by schema.",
+
+		CreateContext: resourceDatabasePermissionCreate,
+		ReadContext:   resourceDatabasePermissionRead,
+		UpdateContext: resourceDatabasePermissionUpdate,
+		DeleteContext: resourceDatabasePermissionDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceDatabasePermissionImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"group_id": {
+				Description: "Permission group id",
+				Type:        schema.TypeInt,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"database_id": {
+				Description: "Database id",
+				Type:        schema.TypeInt,
+				Required:    true,
+				ForceNew:    true,
+			},
+			"view_data": {
+				Description:  "Access to the data of the database: `unrestricted`, `blocked` or `legacy-no-self-service`. Left unchanged when not set",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice(viewDataLevels, false),
+			},
+			"create_queries": {
+				Description:  "Queries the group can create on the whole database: `query-builder-and-native`, `query-builder` or `no`. Must be `no` when granting per schema",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      noCreateQueries,
+				ValidateFunc: validation.StringInSlice(createQueriesLevels, false),
+			},
+			"download_results": {
+				Description:  "Results the group can download from the whole database: `full`, `limited` or `none`. Must be `none` when granting per schema",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      noDownload,
+				ValidateFunc: validation.StringInSlice(downloadLevels, false),
+			},
+			"schema": {
+				Description: "Grants on a schema of the database",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Description: "Schema name, empty for databases without schemas",
+							Type:        schema.TypeString,
+							Required:    true,
+						},
+						"create_queries": {
+							Description:  "Queries the group can create on the whole schema: `query-builder-and-native` or `query-builder`",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice(createQueriesLevels, false),
+						},
+						"download_results": {
+							Description:  "Results the group can download from the whole schema: `full` or `limited`",
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice(downloadLevels, false),
+						},
+						"table": {
+							Description: "Grants on a table of the schema",
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"table_id": {
+										Type:     schema.TypeInt,
+										Required: true,
+									},
+									"create_queries": {
+										Type:         schema.TypeString,
+										Optional:     true,
+										ValidateFunc: validation.StringInSlice(createQueriesLevels, false),
+									},
+									"download_results": {
+										Type:         schema.TypeString,
+										Optional:     true,
+										ValidateFunc: validation.StringInSlice(downloadLevels, false),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceDatabasePermissionCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	groupId := d.Get("group_id").(int)
+	databaseId := d.Get("database_id").(int)
+
+	if diags := updateDatabasePermission(ctx, d, meta); diags.HasError() {
+		return diags
+	}
+
+	d.SetId(fmt.Sprintf("%d:%d", groupId, databaseId))
+	return resourceDatabasePermissionRead(ctx, d, meta)
+}
+
+func resourceDatabasePermissionUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := updateDatabasePermission(ctx, d, meta); diags.HasError() {
+		return diags
+	}
+	return resourceDatabasePermissionRead(ctx, d, meta)
+}
+
+func updateDatabasePermission(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	groupId := d.Get("group_id").(int)
+	databaseId := d.Get("database_id").(int)
+
+	perms, err := expandDatabasePermissions(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	pg := client.PermissionsGraph{
+		Groups: map[string]map[string]client.DatabasePermissions{
+			strconv.Itoa(groupId): {strconv.Itoa(databaseId): perms},
+		},
+	}
+	if _, err := c.UpdatePermissionsGraph(ctx, pg); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error updating group '%d' permissions on database '%d'", groupId, databaseId),
+			Detail:   "Could not update the data permissions, unexpected error: " + err.Error(),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+func resourceDatabasePermissionRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	var diags diag.Diagnostics
+
+	groupId := d.Get("group_id").(int)
+	databaseId := d.Get("database_id").(int)
+
+	pg, err := c.GetGroupPermissionsGraph(ctx, groupId)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error reading group '%d' permissions", groupId),
+			Detail:   "Could not read the data permissions, unexpected error: " + err.Error(),
+		})
+		return diags
+	}
+
+	dbs, found := pg.Groups[strconv.Itoa(groupId)]
+	if !found {
+		log.Printf("[WARN] Group '%d' not found in the permissions graph, removing its permissions from state", groupId)
+		d.SetId("")
+		return diags
+	}
+	// A database without any permission for the group can be left out of the graph
+	perms := dbs[strconv.Itoa(databaseId)]
+
+	if perms.ViewData != nil {
+		viewData, _ := perms.ViewData.(string)
+		if err := d.Set("view_data", viewData); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	var download interface{}
+	if perms.Download != nil {
+		download = perms.Download.Schemas
+	}
+	createQueries, createQueriesSchemas := flattenDatabasePermission(perms.CreateQueries, noCreateQueries)
+	downloadResults, downloadSchemas := flattenDatabasePermission(download, noDownload)
+	if err := d.Set("create_queries", createQueries); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("download_results", downloadResults); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("schema", mergeSchemaPermissions(createQueriesSchemas, downloadSchemas)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+// Removing the resource revokes the group's grants on the database, its access to the data is left unchanged.
+func resourceDatabasePermissionDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	groupId := d.Get("group_id").(int)
+	databaseId := d.Get("database_id").(int)
+
+	pg := client.PermissionsGraph{
+		Groups: map[string]map[string]client.DatabasePermissions{
+			strconv.Itoa(groupId): {strconv.Itoa(databaseId): {
+				CreateQueries: noCreateQueries,
+				Download:      &client.DownloadPermissions{Schemas: noDownload},
+			}},
+		},
+	}
+	if _, err := c.UpdatePermissionsGraph(ctx, pg); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diags
+}
+
+func resourceDatabasePermissionImport(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.Split(d.Id(), ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid import id '%s', expected '<group_id>:<database_id>'", d.Id())
+	}
+	groupId, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid group id '%s': %w", parts[0], err)
+	}
+	databaseId, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid database id '%s': %w", parts[1], err)
+	}
+	if err := d.Set("group_id", groupId); err != nil {
+		return nil, err
+	}
+	if err := d.Set("database_id", databaseId); err != nil {
+		return nil, err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+func expandDatabasePermissions(d *schema.ResourceData) (client.DatabasePermissions, error) {
+	createQueries := d.Get("create_queries").(string)
+	downloadResults := d.Get("download_results").(string)
+	createQueriesSchemas := map[string]interface{}{}
+	downloadSchemas := map[string]interface{}{}
+
+	for _, s := range d.Get("schema").(*schema.Set).List() {
+		s := s.(map[string]interface{})
+		name := s["name"].(string)
+		schemaCreateQueries := s["create_queries"].(string)
+		schemaDownload := s["download_results"].(string)
+		createQueriesTables := map[string]interface{}{}
+		downloadTables := map[string]interface{}{}
+		for _, t := range s["table"].(*schema.Set).List() {
+			t := t.(map[string]interface{})
+			tableId := strconv.Itoa(t["table_id"].(int))
+			if v := t["create_queries"].(string); v != "" && v != noCreateQueries {
+				createQueriesTables[tableId] = v
+			}
+			if v := t["download_results"].(string); v != "" && v != noDownload {
+				downloadTables[tableId] = v
+			}
+		}
+
+		if err := addSchemaPermission(createQueriesSchemas, name, schemaCreateQueries, noCreateQueries, createQueriesTables); err != nil {
+			return client.DatabasePermissions{}, fmt.Errorf("schema '%s' create_queries: %w", name, err)
+		}
+		if err := addSchemaPermission(downloadSchemas, name, schemaDownload, noDownload, downloadTables); err != nil {
+			return client.DatabasePermissions{}, fmt.Errorf("schema '%s' download_results: %w", name, err)
+		}
+	}
+
+	if len(createQueriesSchemas) > 0 && createQueries != noCreateQueries {
+		return client.DatabasePermissions{}, fmt.Errorf("create_queries must be '%s' when granted per schema", noCreateQueries)
+	}
+	if len(downloadSchemas) > 0 && downloadResults != noDownload {
+		return client.DatabasePermissions{}, fmt.Errorf("download_results must be '%s' when granted per schema", noDownload)
+	}
+
+	perms := client.DatabasePermissions{
+		CreateQueries: createQueries,
+		Download:      &client.DownloadPermissions{Schemas: downloadResults},
+	}
+	if len(createQueriesSchemas) > 0 {
+		perms.CreateQueries = createQueriesSchemas
+	}
+	if len(downloadSchemas) > 0 {
+		perms.Download.Schemas = downloadSchemas
+	}
+	if viewData := d.Get("view_data").(string); viewData != "" {
+		perms.ViewData = viewData
+	}
+	return perms, nil
+}
+
+// addSchemaPermission adds the level granted on a schema, or on some of its tables, to a permission's schemas.
+func addSchemaPermission(schemas map[string]interface{}, name string, level string, noLevel string, tables map[string]interface{}) error {
+	switch {
+	case len(tables) > 0 && level != "" && level != noLevel:
+		return fmt.Errorf("cannot be granted on both the schema and its tables")
+	case len(tables) > 0:
+		schemas[name] = tables
+	case level != "" && level != noLevel:
+		schemas[name] = level
+	}
+	return nil
+}
+
+// flattenDatabasePermission splits a permission into the level granted on the whole database and the levels
+// granted by schema, which are either a level or a map of levels by table id.
+func flattenDatabasePermission(v interface{}, noLevel string) (string, map[string]interface{}) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case map[string]interface{}:
+		return noLevel, v
+	}
+	return noLevel, nil
+}
+
+// mergeSchemaPermissions builds the schema blocks from the create-queries and download permissions by schema.
+// Schemas and tables without any grant are left out.
+func mergeSchemaPermissions(createQueries map[string]interface{}, download map[string]interface{}) []interface{} {
+	type tableGrants map[string]map[string]interface{}
+	schemas := map[string]map[string]interface{}{}
+	tables := map[string]tableGrants{}
+
+	add := func(perms map[string]interface{}, key string, noLevel string) {
+		for name, v := range perms {
+			if schemas[name] == nil {
+				schemas[name] = map[string]interface{}{"name": name, "create_queries": "", "download_results": ""}
+				tables[name] = tableGrants{}
+			}
+			switch v := v.(type) {
+			case string:
+				if v != noLevel {
+					schemas[name][key] = v
+				}
+			case map[string]interface{}:
+				for tableId, level := range v {
+					if level == noLevel {
+						continue
+					}
+					if tables[name][tableId] == nil {
+						id, _ := strconv.Atoi(tableId)
+						tables[name][tableId] = map[string]interface{}{"table_id": id, "create_queries": "", "download_results": ""}
+					}
+					tables[name][tableId][key] = level
+				}
+			}
+		}
+	}
+	add(createQueries, "create_queries", noCreateQueries)
+	add(download, "download_results", noDownload)
+
+	result := make([]interface{}, 0, len(schemas))
+	for name, s := range schemas {
+		schemaTables := make([]interface{}, 0, len(tables[name]))
+		for _, t := range tables[name] {
+			schemaTables = append(schemaTables, t)
+		}
+		if s["create_queries"] == "" && s["download_results"] == "" && len(schemaTables) == 0 {
+			continue
+		}
+		s["table"] = schemaTables
+		result = append(result, s)
+	}
+	return result
+}
@@ -0,0 +1,153 @@
+package metabase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"terraform-provider-metabase/client"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceDatabasePermission(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Grant per schema and table writes only the group's slice", func(t *testing.T) {
+		var received client.PermissionsGraph
+		slice := client.PermissionsGraph{Revision: 7, Groups: map[string]map[string]client.DatabasePermissions{
+			"3": {"2": {
+				ViewData: "unrestricted",
+				CreateQueries: map[string]interface{}{
+					"public": "query-builder",
+					"sales":  map[string]interface{}{"12": "query-builder", "13": "no"},
+				},
+				Download: &client.DownloadPermissions{Schemas: map[string]interface{}{"public": "limited", "sales": "none"}},
+			}},
+		}}
+		svr := mockServer(map[string]interface{}{
+			"GET /api/permissions/graph/group/3": slice,
+			"PUT /api/permissions/graph": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				_ = json.NewEncoder(w).Encode(client.PermissionsGraph{Revision: 8})
+			}),
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceDatabasePermission().Schema, map[string]interface{}{
+			"group_id":    3,
+			"database_id": 2,
+			"schema": []interface{}{
+				map[string]interface{}{
+					"name":             "public",
+					"create_queries":   "query-builder",
+					"download_results": "limited",
+				},
+				map[string]interface{}{
+					"name": "sales",
+					"table": []interface{}{
+						map[string]interface{}{"table_id": 12, "create_queries": "query-builder"},
+					},
+				},
+			},
+		})
+
+		diags := resourceDatabasePermissionCreate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, "3:2", d.Id())
+		assert.Equal(t, 7, received.Revision)
+		assert.Len(t, received.Groups, 1)
+		assert.Len(t, received.Groups["3"], 1)
+		assert.Equal(t, map[string]interface{}{
+			"public": "query-builder",
+			"sales":  map[string]interface{}{"12": "query-builder"},
+		}, received.Groups["3"]["2"].CreateQueries)
+		assert.Equal(t, map[string]interface{}{"public": "limited"}, received.Groups["3"]["2"].Download.Schemas)
+		assert.Nil(t, received.Groups["3"]["2"].ViewData)
+
+		assert.Equal(t, "unrestricted", d.Get("view_data"))
+		assert.Equal(t, "no", d.Get("create_queries"))
+		assert.Equal(t, "none", d.Get("download_results"))
+		assert.Equal(t, 2, d.Get("schema").(*schema.Set).Len())
+	})
+
+	t.Run("Read database-wide grants", func(t *testing.T) {
+		slice := client.PermissionsGraph{Revision: 7, Groups: map[string]map[string]client.DatabasePermissions{
+			"3": {"2": {
+				ViewData:      "unrestricted",
+				CreateQueries: "query-builder-and-native",
+				Download:      &client.DownloadPermissions{Schemas: "full"},
+			}},
+		}}
+		svr := mockServer(map[string]interface{}{"GET /api/permissions/graph/group/3": slice})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceDatabasePermission().Schema, map[string]interface{}{})
+		d.SetId("3:2")
+		_, err := resourceDatabasePermissionImport(context.Background(), d, nil)
+		assert.Nil(t, err)
+
+		diags := resourceDatabasePermissionRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, 3, d.Get("group_id"))
+		assert.Equal(t, 2, d.Get("database_id"))
+		assert.Equal(t, "query-builder-and-native", d.Get("create_queries"))
+		assert.Equal(t, "full", d.Get("download_results"))
+		assert.Equal(t, 0, d.Get("schema").(*schema.Set).Len())
+	})
+
+	t.Run("Remove permissions of a deleted group from state", func(t *testing.T) {
+		slice := client.PermissionsGraph{Revision: 7, Groups: map[string]map[string]client.DatabasePermissions{}}
+		svr := mockServer(map[string]interface{}{"GET /api/permissions/graph/group/3": slice})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceDatabasePermission().Schema, map[string]interface{}{
+			"group_id":    3,
+			"database_id": 2,
+		})
+		d.SetId("3:2")
+
+		diags := resourceDatabasePermissionRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "", d.Id())
+	})
+
+	t.Run("Reject database-wide and per schema grants together", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, resourceDatabasePermission().Schema, map[string]interface{}{
+			"group_id":       3,
+			"database_id":    2,
+			"create_queries": "query-builder",
+			"schema": []interface{}{
+				map[string]interface{}{"name": "public", "create_queries": "query-builder-and-native"},
+			},
+		})
+
+		_, err := expandDatabasePermissions(d)
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Delete revokes the grants", func(t *testing.T) {
+		var received client.PermissionsGraph
+		svr := mockServer(map[string]interface{}{
+			"GET /api/permissions/graph/group/3": client.PermissionsGraph{Revision: 7},
+			"PUT /api/permissions/graph": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				_ = json.NewEncoder(w).Encode(client.PermissionsGraph{Revision: 8})
+			}),
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceDatabasePermission().Schema, map[string]interface{}{
+			"group_id":    3,
+			"database_id": 2,
+		})
+		d.SetId("3:2")
+
+		diags := resourceDatabasePermissionDelete(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "no", received.Groups["3"]["2"].CreateQueries)
+		assert.Equal(t, "none", received.Groups["3"]["2"].Download.Schemas)
+	})
+}
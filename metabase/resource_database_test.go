@@ -0,0 +1,135 @@
+package metabase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"terraform-provider-metabase/client"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlattenDatabaseDetails(t *testing.T) {
+	t.Parallel()
+
+	details := map[string]interface{}{
+		"host":                        "db.example.com",
+		"port":                        float64(5432),
+		"password":                    client.RedactedValue,
+		"tunnel-pass":                 client.RedactedValue,
+		"ssl":                         false,
+		"let-user-control-scheduling": false,
+	}
+
+	t.Run("Keep configured keys only", func(t *testing.T) {
+		flattened, err := flattenDatabaseDetails(details, `{"port": 5432, "host": "db.example.com"}`, `{"password": "secret"}`)
+
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"host": "db.example.com", "port": 5432}`, flattened)
+	})
+
+	t.Run("Keep all non-sensitive keys on import", func(t *testing.T) {
+		flattened, err := flattenDatabaseDetails(details, "", "")
+
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"host": "db.example.com", "port": 5432, "ssl": false, "let-user-control-scheduling": false}`, flattened)
+	})
+
+	t.Run("Never store sensitive keys", func(t *testing.T) {
+		flattened, err := flattenDatabaseDetails(details, `{"host": "db.example.com", "password": "secret"}`, `{"password": "secret"}`)
+
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"host": "db.example.com"}`, flattened)
+	})
+}
+
+func TestResourceDatabase(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Create database with secure details", func(t *testing.T) {
+		var received client.Database
+		svr := mockServer(map[string]interface{}{
+			"POST /api/database": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				created := client.Database{
+					Id:             3,
+					Name:           received.Name,
+					Engine:         received.Engine,
+					Details:        map[string]interface{}{"host": "db.example.com", "password": client.RedactedValue, "ssl": true},
+					AutoRunQueries: true,
+					IsFullSync:     true,
+				}
+				_ = json.NewEncoder(w).Encode(created)
+			}),
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceDatabase().Schema, map[string]interface{}{
+			"name":           "Warehouse",
+			"engine":         "postgres",
+			"details":        `{"host": "db.example.com"}`,
+			"details_secure": `{"password": "secret"}`,
+		})
+
+		diags := resourceDatabaseCreate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "3", d.Id())
+		assert.Equal(t, "secret", received.Details["password"])
+		assert.Equal(t, "db.example.com", received.Details["host"])
+		assert.JSONEq(t, `{"host": "db.example.com"}`, d.Get("details").(string))
+		assert.Equal(t, `{"password": "secret"}`, d.Get("details_secure"))
+	})
+
+	t.Run("Keep the stored secrets on update without details_secure", func(t *testing.T) {
+		var received client.Database
+		current := client.Database{
+			Id:      3,
+			Name:    "Warehouse",
+			Engine:  "postgres",
+			Details: map[string]interface{}{"host": "db.example.com", "password": client.RedactedValue, "ssl": true},
+		}
+		svr := mockServer(map[string]interface{}{
+			"GET /api/database/3": current,
+			"PUT /api/database/3": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				_ = json.NewEncoder(w).Encode(received)
+			}),
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceDatabase().Schema, map[string]interface{}{
+			"name":    "Data Warehouse",
+			"engine":  "postgres",
+			"details": `{"host": "db.example.com"}`,
+		})
+		d.SetId("3")
+
+		diags := resourceDatabaseUpdate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, "Data Warehouse", received.Name)
+		assert.Equal(t, client.RedactedValue, received.Details["password"])
+		assert.NotContains(t, received.Details, "ssl")
+	})
+
+	t.Run("Remove database deleted out-of-band from state", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceDatabase().Schema, map[string]interface{}{})
+		d.SetId("3")
+
+		diags := resourceDatabaseRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "", d.Id())
+	})
+}
+
+func TestSuppressEquivalentJSON(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, suppressEquivalentJSON("", `{"a": 1, "b": [1, 2]}`, `{"b":[1,2],"a":1}`, nil))
+	assert.False(t, suppressEquivalentJSON("", `{"a": 1}`, `{"a": 2}`, nil))
+	assert.False(t, suppressEquivalentJSON("", `{"a": 1}`, `not json`, nil))
+}
@@ -2,6 +2,7 @@ package metabase
 
 import (
 	"context"
+	"log"
 	"strconv"
 	"terraform-provider-metabase/client"
 
@@ -38,17 +39,17 @@ func resourceMembership() *schema.Resource {
 	}
 }
 
-func resourceMembershipDelete(_ context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
+func resourceMembershipDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	c := meta.(*client.Client)
 	membershipId, _ := strconv.Atoi(d.Id())
 
-	if err := c.DeleteMembership(membershipId); err != nil {
+	if err := c.DeleteMembership(ctx, membershipId); err != nil && !client.IsNotFound(err) {
 		return diag.Errorf("error deleting membership: %s for membershipId=[%d]", err, membershipId)
 	}
 	return
 }
 
-func resourceMembershipCreate(_ context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
+func resourceMembershipCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	c := meta.(*client.Client)
 	userId := d.Get("user_id").(int)
 	groupId := d.Get("group_id").(int)
@@ -57,7 +58,7 @@ func resourceMembershipCreate(_ context.Context, d *schema.ResourceData, meta in
 		GroupId: groupId,
 	}
 
-	created, err := c.CreateMembership(m)
+	created, err := c.CreateMembership(ctx, m)
 	if err != nil {
 		return diag.Errorf("error creating membership: %s for userId=[%d]", err, userId)
 	}
@@ -75,13 +76,13 @@ func resourceMembershipCreate(_ context.Context, d *schema.ResourceData, meta in
 	return
 }
 
-func resourceMembershipRead(_ context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
+func resourceMembershipRead(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
 	membershipId, _ := strconv.Atoi(d.Id())
 
 	c := meta.(*client.Client)
 	var m client.Membership
 
-	memberships, err := c.GetMemberships()
+	memberships, err := c.GetMemberships(ctx)
 	if err != nil {
 		return diag.FromErr(err)
 	}
@@ -89,7 +90,9 @@ func resourceMembershipRead(_ context.Context, d *schema.ResourceData, meta inte
 	m = findMatchingMembership(memberships, membershipId)
 
 	if m == (client.Membership{}) {
-		return diag.Errorf("Could not find Membership by id [%d] in memberships[%+v]", membershipId, memberships)
+		log.Printf("[WARN] Membership with id [%d] not found, removing it from state", membershipId)
+		d.SetId("")
+		return
 	}
 
 	if err := d.Set("user_id", m.UserId); err != nil {
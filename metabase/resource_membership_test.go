@@ -0,0 +1,59 @@
+package metabase
+
+import (
+	"context"
+	"terraform-provider-metabase/client"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceMembershipRead(t *testing.T) {
+	t.Parallel()
+
+	memberships := client.Memberships{
+		1: []client.Membership{{UserId: 1, GroupId: 3, MembershipId: 7}},
+	}
+
+	t.Run("Read existing membership", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/permissions/membership": memberships})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceMembership().Schema, map[string]interface{}{})
+		d.SetId("7")
+
+		diags := resourceMembershipRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "7", d.Id())
+		assert.Equal(t, 1, d.Get("user_id"))
+		assert.Equal(t, 3, d.Get("group_id"))
+	})
+
+	t.Run("Remove membership deleted out-of-band from state", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/permissions/membership": memberships})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceMembership().Schema, map[string]interface{}{})
+		d.SetId("8")
+
+		diags := resourceMembershipRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "", d.Id())
+	})
+}
+
+func TestResourceMembershipDelete(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Delete membership already deleted out-of-band", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceMembership().Schema, map[string]interface{}{})
+		d.SetId("7")
+
+		diags := resourceMembershipDelete(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+	})
+}
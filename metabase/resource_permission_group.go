@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
 	"terraform-provider-metabase/client"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -12,14 +13,42 @@ import (
 
 func resourcePermissionGroup() *schema.Resource {
 	return &schema.Resource{
+		Description: "Renaming a group updates it in place, keeping its members and permissions. The id of the resource is the numeric group id; the state of groups created by earlier versions of the provider, which used the group name, is upgraded automatically.",
+
 		CreateContext: resourcePermissionGroupCreate,
 		ReadContext:   resourcePermissionGroupRead,
+		UpdateContext: resourcePermissionGroupUpdate,
 		DeleteContext: resourcePermissionGroupDelete,
 
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourcePermissionGroupImport,
+		},
+
+		// The id was the group name before version 1, it is now the group id so that groups can be renamed
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourcePermissionGroupV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourcePermissionGroupStateUpgradeV0,
+			},
 		},
 
+		Schema: map[string]*schema.Schema{
+			"group_id": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+		},
+	}
+}
+
+func resourcePermissionGroupV0() *schema.Resource {
+	return &schema.Resource{
 		Schema: map[string]*schema.Schema{
 			"group_id": {
 				Type:     schema.TypeInt,
@@ -34,7 +63,16 @@ func resourcePermissionGroup() *schema.Resource {
 	}
 }
 
-func resourcePermissionGroupCreate(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourcePermissionGroupStateUpgradeV0(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	groupId, ok := rawState["group_id"].(float64)
+	if !ok || groupId == 0 {
+		return nil, fmt.Errorf("permission group '%v' has no group_id in state", rawState["id"])
+	}
+	rawState["id"] = strconv.Itoa(int(groupId))
+	return rawState, nil
+}
+
+func resourcePermissionGroupCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
 
 	// Warning or errors can be collected in a slice type
@@ -53,7 +91,7 @@ func resourcePermissionGroupCreate(_ context.Context, d *schema.ResourceData, me
 	}
 
 	// Create the permission group
-	pg, err := c.CreatePermissionGroup(name)
+	pg, err := c.CreatePermissionGroup(ctx, name)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -63,7 +101,7 @@ func resourcePermissionGroupCreate(_ context.Context, d *schema.ResourceData, me
 		return diags
 	}
 
-	d.SetId(pg.Name)
+	d.SetId(strconv.Itoa(pg.Id))
 	if err := d.Set("group_id", pg.Id); err != nil {
 		return diag.FromErr(err)
 	}
@@ -74,17 +112,15 @@ func resourcePermissionGroupCreate(_ context.Context, d *schema.ResourceData, me
 	return diags
 }
 
-func resourcePermissionGroupRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourcePermissionGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
-	name := d.Id()
+	id, _ := strconv.Atoi(d.Id())
 
 	var diags diag.Diagnostics
 
-	var pg client.PermissionGroup
-
-	log.Printf("[INFO] Finding permissionGroup by name '%s'", name)
-	// fetch all permission groups and find the one with the given name
-	pgs, err := c.GetPermissionGroups()
+	log.Printf("[INFO] Finding permissionGroup by id '%d'", id)
+	// fetch all permission groups and find the one with the given id
+	pgs, err := c.GetPermissionGroups(ctx)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -94,35 +130,52 @@ func resourcePermissionGroupRead(_ context.Context, d *schema.ResourceData, meta
 		return diags
 	}
 
-	for _, i := range pgs {
-		if i.Name == name {
-			pg.Id = i.Id
-			pg.Name = i.Name
-			break
-		}
+	pg, found := findPermissionGroup(pgs, func(pg client.PermissionGroup) bool { return pg.Id == id })
+	if !found {
+		log.Printf("[WARN] PermissionGroup with id '%d' not found, removing it from state", id)
+		d.SetId("")
+		return diags
+	}
+
+	if err := d.Set("group_id", pg.Id); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("name", pg.Name); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourcePermissionGroupUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	pg := client.PermissionGroup{
+		Id:   d.Get("group_id").(int),
+		Name: d.Get("name").(string),
 	}
 
-	if (pg == client.PermissionGroup{}) {
+	updated, err := c.UpdatePermissionGroup(ctx, pg)
+	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
-			Summary:  "Error reading permissionGroup",
-			Detail:   fmt.Sprintf("Could not find permissionGroup with name '%s'", name),
+			Summary:  fmt.Sprintf("Error renaming Permission Group '%d' to '%s'", pg.Id, pg.Name),
+			Detail:   "Could not update Permission Group, unexpected error: " + err.Error(),
 		})
 		return diags
 	}
 
-	d.SetId(pg.Name)
-	if err := d.Set("group_id", pg.Id); err != nil {
-		return diag.FromErr(err)
-	}
-	if err := d.Set("name", pg.Name); err != nil {
+	if err := d.Set("name", updated.Name); err != nil {
 		return diag.FromErr(err)
 	}
 
 	return diags
 }
 
-func resourcePermissionGroupDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourcePermissionGroupDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
 
 	// Warning or errors can be collected in a slice type
@@ -130,8 +183,8 @@ func resourcePermissionGroupDelete(_ context.Context, d *schema.ResourceData, me
 
 	id := d.Get("group_id").(int)
 
-	err := c.DeletePermissionGroup(id)
-	if err != nil {
+	err := c.DeletePermissionGroup(ctx, id)
+	if err != nil && !client.IsNotFound(err) {
 		return diag.FromErr(err)
 	}
 
@@ -141,3 +194,32 @@ func resourcePermissionGroupDelete(_ context.Context, d *schema.ResourceData, me
 
 	return diags
 }
+
+// Groups are imported by id, or by name as they were before the id became the group id.
+func resourcePermissionGroupImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if _, err := strconv.Atoi(d.Id()); err == nil {
+		return []*schema.ResourceData{d}, nil
+	}
+
+	pgs, err := meta.(*client.Client).GetPermissionGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name := d.Id()
+	pg, found := findPermissionGroup(pgs, func(pg client.PermissionGroup) bool { return pg.Name == name })
+	if !found {
+		return nil, fmt.Errorf("could not find permission group with name '%s'", name)
+	}
+	d.SetId(strconv.Itoa(pg.Id))
+	return []*schema.ResourceData{d}, nil
+}
+
+func findPermissionGroup(pgs client.PermissionGroups, match func(client.PermissionGroup) bool) (client.PermissionGroup, bool) {
+	for _, i := range pgs {
+		pg := client.PermissionGroup{Id: i.Id, Name: i.Name}
+		if match(pg) {
+			return pg, true
+		}
+	}
+	return client.PermissionGroup{}, false
+}
@@ -0,0 +1,153 @@
+package metabase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"terraform-provider-metabase/client"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourcePermissionGroupRead(t *testing.T) {
+	t.Parallel()
+
+	groups := client.PermissionGroups{{Id: 3, Name: "Analysts", MemberCount: 1}}
+
+	t.Run("Read existing group", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/permissions/group": groups})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourcePermissionGroup().Schema, map[string]interface{}{})
+		d.SetId("3")
+
+		diags := resourcePermissionGroupRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "3", d.Id())
+		assert.Equal(t, 3, d.Get("group_id"))
+		assert.Equal(t, "Analysts", d.Get("name"))
+	})
+
+	t.Run("Remove group deleted out-of-band from state", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/permissions/group": groups})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourcePermissionGroup().Schema, map[string]interface{}{})
+		d.SetId("4")
+
+		diags := resourcePermissionGroupRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "", d.Id())
+	})
+
+	t.Run("Data source fails on unknown group", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/permissions/group": groups})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, dataSourcePermissionGroup().Schema, map[string]interface{}{
+			"name": "Engineers",
+		})
+
+		diags := dataSourcePermissionGroupRead(context.Background(), d, mockClient(svr))
+
+		assert.True(t, diags.HasError())
+	})
+
+	t.Run("Data source looks up the group by name", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/permissions/group": groups})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, dataSourcePermissionGroup().Schema, map[string]interface{}{
+			"name": "Analysts",
+		})
+
+		diags := dataSourcePermissionGroupRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "3", d.Id())
+		assert.Equal(t, 3, d.Get("group_id"))
+	})
+
+	t.Run("Import group by its former name id", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/permissions/group": groups})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourcePermissionGroup().Schema, map[string]interface{}{})
+		d.SetId("Analysts")
+
+		imported, err := resourcePermissionGroupImport(context.Background(), d, mockClient(svr))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "3", imported[0].Id())
+	})
+}
+
+func TestResourcePermissionGroupUpdate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Rename group in place", func(t *testing.T) {
+		var received client.PermissionGroup
+		svr := mockServer(map[string]interface{}{
+			"PUT /api/permissions/group/3": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				_ = json.NewEncoder(w).Encode(received)
+			}),
+		})
+		defer svr.Close()
+		r := resourcePermissionGroup()
+		state := &terraform.InstanceState{
+			ID:         "3",
+			Attributes: map[string]string{"id": "3", "group_id": "3", "name": "Analysts"},
+		}
+		diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{"name": "Data Analysts"}), nil)
+		assert.Nil(t, err)
+		assert.False(t, diff.RequiresNew())
+		d, err := schema.InternalMap(r.Schema).Data(state, diff)
+		assert.Nil(t, err)
+
+		diags := resourcePermissionGroupUpdate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, client.PermissionGroup{Id: 3, Name: "Data Analysts"}, received)
+		assert.Equal(t, "3", d.Id())
+		assert.Equal(t, "Data Analysts", d.Get("name"))
+	})
+}
+
+func TestResourcePermissionGroupStateUpgradeV0(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Use the group id as id", func(t *testing.T) {
+		upgraded, err := resourcePermissionGroupStateUpgradeV0(context.Background(), map[string]interface{}{
+			"id":       "Analysts",
+			"group_id": float64(3),
+			"name":     "Analysts",
+		}, nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"id": "3", "group_id": float64(3), "name": "Analysts"}, upgraded)
+	})
+
+	t.Run("Fail without group id", func(t *testing.T) {
+		_, err := resourcePermissionGroupStateUpgradeV0(context.Background(), map[string]interface{}{"id": "Analysts"}, nil)
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestResourcePermissionGroupDelete(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Delete group already deleted out-of-band", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourcePermissionGroup().Schema, map[string]interface{}{})
+		d.SetId("3")
+		_ = d.Set("group_id", 3)
+
+		diags := resourcePermissionGroupDelete(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "", d.Id())
+	})
+}
@@ -0,0 +1,157 @@
+package metabase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"terraform-provider-metabase/client"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// rootCollectionId is the id of the "Our analytics" collection in the collection graph.
+const rootCollectionId = "root"
+
+func resourceRootCollectionPermissions() *schema.Resource {
+	return &schema.Resource{
+		Description: "Manages the access of groups to the root collection, \"Our analytics\", including the All Users group (`1`). The Administrators group always has write access and can't be listed. Removing a group from `permissions`, or removing the resource, revokes its access.",
+
+		CreateContext: resourceRootCollectionPermissionsUpdate,
+		ReadContext:   resourceRootCollectionPermissionsRead,
+		UpdateContext: resourceRootCollectionPermissionsUpdate,
+		DeleteContext: resourceRootCollectionPermissionsDelete,
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		CustomizeDiff: resourceRootCollectionPermissionsCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"permissions": {
+				Description: "Access of the groups to the root collection by group id, All Users (`1`) included: `read`, `write` or `none`. Groups not listed are left unchanged",
+				Type:        schema.TypeMap,
+				Required:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				ValidateDiagFunc: validation.MapValueMatch(regexp.MustCompile(`^(read|write|none)$`), "must be one of read, write or none"),
+			},
+		},
+	}
+}
+
+// The keys must be group ids, and the Administrators group always has write access to every collection.
+func resourceRootCollectionPermissionsCustomizeDiff(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+	for groupId := range d.Get("permissions").(map[string]interface{}) {
+		id, err := strconv.Atoi(groupId)
+		if err != nil {
+			return fmt.Errorf("invalid group id '%s' in permissions", groupId)
+		}
+		if id == administratorsGroupId {
+			return fmt.Errorf("the permissions of the Administrators group (%d) can't be changed", administratorsGroupId)
+		}
+	}
+	return nil
+}
+
+func resourceRootCollectionPermissionsUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	oldPermissions, newPermissions := d.GetChange("permissions")
+	permissions := setRemovedPermissionsToNone(oldPermissions.(map[string]interface{}), newPermissions.(map[string]interface{}))
+
+	if diags := updateRootCollectionPermissions(ctx, meta, permissions); diags.HasError() {
+		return diags
+	}
+
+	d.SetId(rootCollectionId)
+	return resourceRootCollectionPermissionsRead(ctx, d, meta)
+}
+
+// updateRootCollectionPermissions only writes the root column of the given groups in the collection graph.
+func updateRootCollectionPermissions(ctx context.Context, meta interface{}, permissions map[string]interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	cg := client.CollectionGraph{Groups: map[string]map[string]string{}}
+	for groupId, access := range permissions {
+		cg.Groups[groupId] = map[string]string{rootCollectionId: access.(string)}
+	}
+
+	if _, err := c.UpdateCollectionGraph(ctx, cg); err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Error updating the root collection permissions",
+			Detail:   "Could not update the collection permissions, unexpected error: " + err.Error(),
+		})
+		return diags
+	}
+
+	return diags
+}
+
+func resourceRootCollectionPermissionsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	var diags diag.Diagnostics
+
+	cg, err := c.GetCollectionGraph(ctx)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  "Error reading the root collection permissions",
+			Detail:   "Could not read the collection permissions, unexpected error: " + err.Error(),
+		})
+		return diags
+	}
+
+	// Only the groups set in the permissions attribute are managed. Every group is picked up on import,
+	// when there is no state yet.
+	managed := d.Get("permissions").(map[string]interface{})
+	permissions := make(map[string]string)
+	for groupId, collections := range cg.Groups {
+		if groupId == strconv.Itoa(administratorsGroupId) {
+			continue
+		}
+		access, found := collections[rootCollectionId]
+		if !found {
+			continue
+		}
+		if _, isManaged := managed[groupId]; isManaged || (len(managed) == 0 && access != noCollectionAccess) {
+			permissions[groupId] = access
+		}
+	}
+	for groupId := range managed {
+		if _, found := permissions[groupId]; !found {
+			log.Printf("[WARN] Group '%s' not found in the collection graph, removing it from the root collection permissions", groupId)
+		}
+	}
+
+	if err := d.Set("permissions", permissions); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+// Removing the resource revokes the access of the managed groups to the root collection.
+func resourceRootCollectionPermissionsDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	permissions := make(map[string]interface{})
+	for groupId := range d.Get("permissions").(map[string]interface{}) {
+		permissions[groupId] = noCollectionAccess
+	}
+
+	if diags := updateRootCollectionPermissions(ctx, meta, permissions); diags.HasError() {
+		return diags
+	}
+
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
@@ -0,0 +1,101 @@
+package metabase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"terraform-provider-metabase/client"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceRootCollectionPermissions(t *testing.T) {
+	t.Parallel()
+
+	cg := client.CollectionGraph{
+		Revision: 4,
+		Groups: map[string]map[string]string{
+			"1": {"root": "read", "5": "write"},
+			"2": {"root": "write", "5": "write"},
+			"3": {"root": "write", "5": "none"},
+			"4": {"root": "none", "5": "none"},
+		},
+	}
+
+	t.Run("Write the root column of the listed groups", func(t *testing.T) {
+		var received client.CollectionGraph
+		svr := mockServer(map[string]interface{}{
+			"GET /api/collection/graph": cg,
+			"PUT /api/collection/graph": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				_ = json.NewEncoder(w).Encode(received)
+			}),
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceRootCollectionPermissions().Schema, map[string]interface{}{
+			"permissions": map[string]interface{}{"1": "read", "4": "none"},
+		})
+
+		diags := resourceRootCollectionPermissionsUpdate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, "root", d.Id())
+		assert.Equal(t, map[string]map[string]string{"1": {"root": "read"}, "4": {"root": "none"}}, received.Groups)
+		assert.Equal(t, map[string]interface{}{"1": "read", "4": "none"}, d.Get("permissions"))
+	})
+
+	t.Run("Revoke groups removed from the permissions", func(t *testing.T) {
+		var received client.CollectionGraph
+		svr := mockServer(map[string]interface{}{
+			"GET /api/collection/graph": cg,
+			"PUT /api/collection/graph": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				_ = json.NewEncoder(w).Encode(received)
+			}),
+		})
+		defer svr.Close()
+		r := resourceRootCollectionPermissions()
+		state := &terraform.InstanceState{
+			ID:         "root",
+			Attributes: map[string]string{"id": "root", "permissions.%": "2", "permissions.1": "read", "permissions.3": "write"},
+		}
+		cfg := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"permissions": map[string]interface{}{"1": "read"},
+		})
+		diff, err := r.Diff(context.Background(), state, cfg, nil)
+		assert.Nil(t, err)
+		d, err := schema.InternalMap(r.Schema).Data(state, diff)
+		assert.Nil(t, err)
+
+		diags := resourceRootCollectionPermissionsUpdate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, map[string]map[string]string{"1": {"root": "read"}, "3": {"root": "none"}}, received.Groups)
+	})
+
+	t.Run("Import every group with access but the Administrators", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/collection/graph": cg})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceRootCollectionPermissions().Schema, map[string]interface{}{})
+		d.SetId("root")
+
+		diags := resourceRootCollectionPermissionsRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, map[string]interface{}{"1": "read", "3": "write"}, d.Get("permissions"))
+	})
+
+	t.Run("Reject the Administrators group", func(t *testing.T) {
+		r := resourceRootCollectionPermissions()
+		cfg := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"permissions": map[string]interface{}{"2": "read"},
+		})
+
+		_, err := r.Diff(context.Background(), nil, cfg, nil)
+
+		assert.ErrorContains(t, err, "Administrators")
+	})
+}
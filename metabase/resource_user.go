@@ -8,10 +8,20 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// Every user is a member of the All Users group, and of the Administrators group when superuser. These memberships
+// are handled by Metabase and is_superuser, so they are left out of group_ids.
+const (
+	allUsersGroupId       = 1
+	administratorsGroupId = 2
 )
 
 func resourceUser() *schema.Resource {
 	return &schema.Resource{
+		Description: "Destroying a user deactivates it, as Metabase never deletes users. Creating a user with the email of a deactivated user reactivates it.",
+
 		CreateContext: resourceUserCreate,
 		ReadContext:   resourceUserRead,
 		UpdateContext: resourceUserUpdate,
@@ -38,29 +48,72 @@ func resourceUser() *schema.Resource {
 				Type:     schema.TypeString,
 				Required: true,
 			},
+			"is_superuser": {
+				Description: "Whether the user is an administrator, left unchanged when not set",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+			},
+			"login_attributes": {
+				Description: "Attributes used by data sandboxing. Only string values are supported: the other values are left out, and a user having some can't be updated",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"locale": {
+				Description: "User locale, e.g. `en` or `fr`, the site locale when not set",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"is_active": {
+				Description: "Whether the user is active, deleted users are only deactivated by Metabase",
+				Type:        schema.TypeBool,
+				Computed:    true,
+			},
+			"group_ids": {
+				Description: "Ids of the permission groups of the user, besides All Users and Administrators. " +
+					"Memberships are only managed when set, don't use along with `metabase_membership` for the same user",
+				Type:     schema.TypeSet,
+				Optional: true,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeInt,
+					ValidateFunc: validation.IntNotInSlice([]int{allUsersGroupId, administratorsGroupId}),
+				},
+			},
 		},
 	}
 }
 
-func resourceUserUpdate(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
 
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	firstName := d.Get("first_name").(string)
-	lastName := d.Get("last_name").(string)
 	email := d.Get("email").(string)
 	userId := d.Get("user_id").(int)
-	u := client.User{
-		Id:        userId,
-		FirstName: firstName,
-		LastName:  lastName,
-		Email:     email,
+	u := expandUser(d)
+	u.Id = userId
+
+	// The configured login attributes replace the ones of the user
+	current, err := c.GetUser(ctx, userId)
+	if err == nil {
+		err = checkLoginAttributes(current)
+	}
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error updating User '%s'", email),
+			Detail:   "Could not update User: " + err.Error(),
+		})
+		return diags
 	}
 
 	// Update the user
-	updated, err := c.UpdateUser(u, userId)
+	updated, err := c.UpdateUser(ctx, u, userId)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -70,78 +123,126 @@ func resourceUserUpdate(_ context.Context, d *schema.ResourceData, meta interfac
 		return diags
 	}
 
-	d.SetId(updated.Email)
-	if err := d.Set("first_name", updated.FirstName); err != nil {
-		return diag.FromErr(err)
-	}
-	if err := d.Set("last_name", updated.LastName); err != nil {
-		return diag.FromErr(err)
-	}
-	if err := d.Set("email", updated.Email); err != nil {
-		return diag.FromErr(err)
+	groupIds, err := userGroupIds(ctx, c, d, updated.Id, d.HasChange("group_ids"))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error updating User '%s' groups", email),
+			Detail:   "Could not update the User memberships, unexpected error: " + err.Error(),
+		})
+		return diags
 	}
-	if err := d.Set("user_id", updated.Id); err != nil {
+
+	d.SetId(updated.Email)
+	if err := setUser(d, updated, groupIds); err != nil {
 		return diag.FromErr(err)
 	}
 
 	return diags
 }
 
-func resourceUserCreate(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceUserCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
 
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
-	firstName := d.Get("first_name").(string)
-	lastName := d.Get("last_name").(string)
 	email := d.Get("email").(string)
-	u := client.User{
-		FirstName: firstName,
-		LastName:  lastName,
-		Email:     email,
-	}
+	u := expandUser(d)
 
-	// Create the user
-	created, err := c.CreateUser(u)
+	// Deleted users are only deactivated, and their email can't be used by a new user
+	deactivated, err := findDeactivatedUser(ctx, c, email)
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
 			Summary:  fmt.Sprintf("Error creating User '%s'", email),
-			Detail:   "Could not create User, unexpected error: " + err.Error(),
+			Detail:   "Could not look for a deactivated User, unexpected error: " + err.Error(),
 		})
 		return diags
 	}
 
-	d.SetId(created.Email)
-	if err := d.Set("first_name", created.FirstName); err != nil {
-		return diag.FromErr(err)
+	var created client.User
+	if deactivated != nil {
+		// The reactivated user is updated with the configured login attributes
+		if err := checkLoginAttributes(*deactivated); err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Error reactivating User '%s'", email),
+				Detail:   "Could not reactivate User: " + err.Error(),
+			})
+			return diags
+		}
+		log.Printf("[INFO] Reactivating user with email '%s'", email)
+		created, err = c.ReactivateUser(ctx, deactivated.Id)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Error reactivating User '%s'", email),
+				Detail:   "Could not reactivate User, unexpected error: " + err.Error(),
+			})
+			return diags
+		}
+	} else {
+		// Create the user
+		created, err = c.CreateUser(ctx, u)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Error creating User '%s'", email),
+				Detail:   "Could not create User, unexpected error: " + err.Error(),
+			})
+			return diags
+		}
 	}
-	if err := d.Set("last_name", created.LastName); err != nil {
-		return diag.FromErr(err)
+	d.SetId(created.Email)
+
+	// The superuser flag and the locale can only be set on existing users, a reactivated user keeps its former
+	// attributes until updated
+	if u.IsSuperuser || u.Locale != nil || deactivated != nil {
+		u.Id = created.Id
+		created, err = c.UpdateUser(ctx, u, created.Id)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Error updating User '%s'", email),
+				Detail:   "Could not update User, unexpected error: " + err.Error(),
+			})
+			return diags
+		}
 	}
-	if err := d.Set("email", created.Email); err != nil {
-		return diag.FromErr(err)
+
+	_, hasGroups := d.GetOk("group_ids")
+	groupIds, err := userGroupIds(ctx, c, d, created.Id, hasGroups)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Error adding User '%s' to groups", email),
+			Detail:   "Could not create the User memberships, unexpected error: " + err.Error(),
+		})
+		return diags
 	}
-	if err := d.Set("user_id", created.Id); err != nil {
+
+	if err := setUser(d, created, groupIds); err != nil {
 		return diag.FromErr(err)
 	}
 
 	return diags
 }
 
-func resourceUserRead(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceUserRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
 
 	email := d.Id()
 
 	var diags diag.Diagnostics
 
-	var user client.User
-
-	// fetch all users and find the one with the given email
 	log.Printf("[INFO] Finding user by email '%s'", email)
-	users, err := c.GetUsers()
+	user, err := c.GetUserByEmail(ctx, email, false)
+	if client.IsNotFound(err) {
+		log.Printf("[WARN] User with email '%s' not found, removing it from state", email)
+		d.SetId("")
+		return diags
+	}
 	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
@@ -150,40 +251,26 @@ func resourceUserRead(_ context.Context, d *schema.ResourceData, meta interface{
 		})
 		return diags
 	}
-	for _, i := range users.Data {
-		if i.Email == email {
-			user = i
-			break
-		}
-	}
 
-	if (user == client.User{}) {
+	groupIds, err := userGroupIds(ctx, c, d, user.Id, false)
+	if err != nil {
 		diags = append(diags, diag.Diagnostic{
 			Severity: diag.Error,
-			Summary:  "Error reading user",
-			Detail:   fmt.Sprintf("Could not find user with email '%s'", email),
+			Summary:  "Error reading",
+			Detail:   "Could not read memberships: " + err.Error(),
 		})
 		return diags
 	}
 
 	d.SetId(user.Email)
-	if err := d.Set("user_id", user.Id); err != nil {
-		return diag.FromErr(err)
-	}
-	if err := d.Set("first_name", user.FirstName); err != nil {
-		return diag.FromErr(err)
-	}
-	if err := d.Set("last_name", user.LastName); err != nil {
-		return diag.FromErr(err)
-	}
-	if err := d.Set("email", user.Email); err != nil {
+	if err := setUser(d, user, groupIds); err != nil {
 		return diag.FromErr(err)
 	}
 
 	return diags
 }
 
-func resourceUserDelete(_ context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+func resourceUserDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
 
 	// Warning or errors can be collected in a slice type
@@ -191,8 +278,8 @@ func resourceUserDelete(_ context.Context, d *schema.ResourceData, meta interfac
 
 	id := d.Get("user_id").(int)
 
-	_, err := c.DeleteUser(id)
-	if err != nil {
+	_, err := c.DeleteUser(ctx, id)
+	if err != nil && !client.IsNotFound(err) {
 		return diag.FromErr(err)
 	}
 
@@ -202,3 +289,126 @@ func resourceUserDelete(_ context.Context, d *schema.ResourceData, meta interfac
 
 	return diags
 }
+
+func findDeactivatedUser(ctx context.Context, c *client.Client, email string) (*client.User, error) {
+	user, err := c.GetUserByEmail(ctx, email, true)
+	if client.IsNotFound(err) || (err == nil && user.IsActive) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func expandUser(d *schema.ResourceData) client.User {
+	u := client.User{
+		FirstName:       d.Get("first_name").(string),
+		LastName:        d.Get("last_name").(string),
+		Email:           d.Get("email").(string),
+		IsSuperuser:     d.Get("is_superuser").(bool),
+		LoginAttributes: d.Get("login_attributes").(map[string]interface{}),
+	}
+	if locale := d.Get("locale").(string); locale != "" {
+		u.Locale = &locale
+	}
+	return u
+}
+
+// userGroupIds returns the groups the user is a member of. When sync is set, memberships are first created or
+// deleted to match the configured group_ids.
+func userGroupIds(ctx context.Context, c *client.Client, d *schema.ResourceData, userId int, sync bool) ([]int, error) {
+	memberships, err := c.GetMemberships(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groupIds := []int{}
+	wanted := map[int]bool{}
+	for _, id := range d.Get("group_ids").(*schema.Set).List() {
+		wanted[id.(int)] = true
+	}
+	current := map[int]bool{}
+	for _, m := range memberships[userId] {
+		if m.GroupId == allUsersGroupId || m.GroupId == administratorsGroupId {
+			continue
+		}
+		current[m.GroupId] = true
+		if !sync || wanted[m.GroupId] {
+			groupIds = append(groupIds, m.GroupId)
+			continue
+		}
+		if err := c.DeleteMembership(ctx, m.MembershipId); err != nil && !client.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	if !sync {
+		return groupIds, nil
+	}
+	for groupId := range wanted {
+		if current[groupId] {
+			continue
+		}
+		if _, err := c.CreateMembership(ctx, client.Membership{UserId: userId, GroupId: groupId}); err != nil {
+			return nil, err
+		}
+		groupIds = append(groupIds, groupId)
+	}
+	return groupIds, nil
+}
+
+// checkLoginAttributes rejects updating a user with login attributes that aren't strings, as the configured attributes,
+// which can only be strings, would replace them.
+func checkLoginAttributes(user client.User) error {
+	for key, value := range user.LoginAttributes {
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("login attribute '%s' of user '%s' is not a string but %T, which is not supported", key, user.Email, value)
+		}
+	}
+	return nil
+}
+
+func setUser(d *schema.ResourceData, user client.User, groupIds []int) error {
+	if err := d.Set("user_id", user.Id); err != nil {
+		return err
+	}
+	if err := d.Set("first_name", user.FirstName); err != nil {
+		return err
+	}
+	if err := d.Set("last_name", user.LastName); err != nil {
+		return err
+	}
+	if err := d.Set("email", user.Email); err != nil {
+		return err
+	}
+	if err := d.Set("is_active", user.IsActive); err != nil {
+		return err
+	}
+	if err := d.Set("is_superuser", user.IsSuperuser); err != nil {
+		return err
+	}
+	loginAttributes := make(map[string]interface{}, len(user.LoginAttributes))
+	for key, value := range user.LoginAttributes {
+		s, ok := value.(string)
+		if !ok {
+			// Reading is fine, only writing the attributes back is rejected by checkLoginAttributes
+			log.Printf("[WARN] Login attribute '%s' of user '%s' is not a string but %T, leaving it out", key, user.Email, value)
+			continue
+		}
+		loginAttributes[key] = s
+	}
+	if err := d.Set("login_attributes", loginAttributes); err != nil {
+		return err
+	}
+	locale := ""
+	if user.Locale != nil {
+		locale = *user.Locale
+	}
+	if err := d.Set("locale", locale); err != nil {
+		return err
+	}
+	if err := d.Set("group_ids", groupIds); err != nil {
+		return err
+	}
+	return nil
+}
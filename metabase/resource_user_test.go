@@ -0,0 +1,296 @@
+package metabase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"terraform-provider-metabase/client"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceUserRead(t *testing.T) {
+	t.Parallel()
+
+	users := client.Users{Data: []client.User{{
+		Id:        1,
+		Email:     "john.doe@example.com",
+		FirstName: "John",
+		LastName:  "Doe",
+	}}}
+	memberships := client.Memberships{1: {
+		{UserId: 1, GroupId: 1, MembershipId: 10},
+		{UserId: 1, GroupId: 2, MembershipId: 11},
+		{UserId: 1, GroupId: 5, MembershipId: 12},
+	}}
+
+	t.Run("Read existing user", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{
+			"GET /api/user":                   users,
+			"GET /api/permissions/membership": memberships,
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{})
+		d.SetId("john.doe@example.com")
+
+		diags := resourceUserRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "john.doe@example.com", d.Id())
+		assert.Equal(t, 1, d.Get("user_id"))
+		assert.Equal(t, "John", d.Get("first_name"))
+		// All Users and Administrators memberships are left out
+		assert.Equal(t, []interface{}{5}, d.Get("group_ids").(*schema.Set).List())
+	})
+
+	t.Run("Leave out login attributes that aren't strings", func(t *testing.T) {
+		sandboxed := users
+		sandboxed.Data = []client.User{users.Data[0]}
+		sandboxed.Data[0].LoginAttributes = map[string]interface{}{"ids": []interface{}{1, 2}, "region": "emea"}
+		svr := mockServer(map[string]interface{}{
+			"GET /api/user":                   sandboxed,
+			"GET /api/permissions/membership": memberships,
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{})
+		d.SetId("john.doe@example.com")
+
+		diags := resourceUserRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError(), diags)
+		assert.Equal(t, map[string]interface{}{"region": "emea"}, d.Get("login_attributes"))
+	})
+
+	t.Run("Keep the superuser flag when it isn't configured", func(t *testing.T) {
+		state := &terraform.InstanceState{
+			ID: "john.doe@example.com",
+			Attributes: map[string]string{
+				"id":           "john.doe@example.com",
+				"email":        "john.doe@example.com",
+				"first_name":   "John",
+				"last_name":    "Doe",
+				"is_superuser": "true",
+			},
+		}
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"email":      "john.doe@example.com",
+			"first_name": "John",
+			"last_name":  "Doe",
+		})
+
+		diff, err := resourceUser().Diff(context.Background(), state, config, nil)
+
+		assert.Nil(t, err)
+		if diff != nil {
+			assert.NotContains(t, diff.Attributes, "is_superuser")
+		}
+	})
+
+	t.Run("Remove user deleted out-of-band from state", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/user": users})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{})
+		d.SetId("jane.doe@example.com")
+
+		diags := resourceUserRead(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "", d.Id())
+	})
+
+	t.Run("Data source fails on unknown user", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{"GET /api/user": users})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, dataSourceUser().Schema, map[string]interface{}{
+			"email": "jane.doe@example.com",
+		})
+
+		diags := dataSourceUserRead(context.Background(), d, mockClient(svr))
+
+		assert.True(t, diags.HasError())
+	})
+}
+
+func TestResourceUserCreate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Create superuser with attributes and groups", func(t *testing.T) {
+		var updated client.User
+		var createdMemberships []client.Membership
+		svr := mockServer(map[string]interface{}{
+			"GET /api/user":  client.Users{Data: []client.User{}},
+			"POST /api/user": client.User{Id: 3, Email: "jane.doe@example.com", FirstName: "Jane", LastName: "Doe"},
+			"PUT /api/user/3": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&updated)
+				_ = json.NewEncoder(w).Encode(updated)
+			}),
+			"GET /api/permissions/membership": client.Memberships{3: {{UserId: 3, GroupId: 1, MembershipId: 20}}},
+			"POST /api/permissions/membership": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var m client.Membership
+				_ = json.NewDecoder(r.Body).Decode(&m)
+				createdMemberships = append(createdMemberships, m)
+				_, _ = w.Write([]byte(`[{"user_id": 3, "membership_id": 21}]`))
+			}),
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{
+			"email":            "jane.doe@example.com",
+			"first_name":       "Jane",
+			"last_name":        "Doe",
+			"is_superuser":     true,
+			"locale":           "fr",
+			"login_attributes": map[string]interface{}{"region": "emea"},
+			"group_ids":        []interface{}{5},
+		})
+
+		diags := resourceUserCreate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "jane.doe@example.com", d.Id())
+		assert.True(t, updated.IsSuperuser)
+		assert.Equal(t, "fr", *updated.Locale)
+		assert.Equal(t, map[string]interface{}{"region": "emea"}, updated.LoginAttributes)
+		assert.Equal(t, []client.Membership{{UserId: 3, GroupId: 5}}, createdMemberships)
+		assert.True(t, d.Get("is_superuser").(bool))
+		assert.Equal(t, "emea", d.Get("login_attributes.region"))
+		assert.Equal(t, []interface{}{5}, d.Get("group_ids").(*schema.Set).List())
+	})
+}
+
+func TestResourceUserUpdate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Reject login attributes that aren't strings", func(t *testing.T) {
+		updated := false
+		svr := mockServer(map[string]interface{}{
+			"GET /api/user/1": client.User{
+				Id:              1,
+				Email:           "john.doe@example.com",
+				LoginAttributes: map[string]interface{}{"ids": []interface{}{1, 2}},
+			},
+			"PUT /api/user/1": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				updated = true
+			}),
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{
+			"email":      "john.doe@example.com",
+			"first_name": "Johnny",
+			"last_name":  "Doe",
+		})
+		d.SetId("john.doe@example.com")
+		_ = d.Set("user_id", 1)
+
+		diags := resourceUserUpdate(context.Background(), d, mockClient(svr))
+
+		assert.True(t, diags.HasError())
+		assert.False(t, updated)
+	})
+}
+
+func TestResourceUserReactivate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Reactivate deactivated user with the same email", func(t *testing.T) {
+		var updated client.User
+		svr := mockServer(map[string]interface{}{
+			"GET /api/user": client.Users{Data: []client.User{
+				{Id: 3, Email: "jane.doe@example.com", FirstName: "Jane", LastName: "Smith", IsActive: false},
+			}},
+			"POST /api/user": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"errors": {"email": "Email address already in use."}}`))
+			}),
+			"PUT /api/user/3/reactivate": client.User{Id: 3, Email: "jane.doe@example.com", FirstName: "Jane", LastName: "Smith", IsActive: true},
+			"PUT /api/user/3": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&updated)
+				updated.IsActive = true
+				_ = json.NewEncoder(w).Encode(updated)
+			}),
+			"GET /api/permissions/membership": client.Memberships{},
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{
+			"email":      "jane.doe@example.com",
+			"first_name": "Jane",
+			"last_name":  "Doe",
+		})
+
+		diags := resourceUserCreate(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "jane.doe@example.com", d.Id())
+		assert.Equal(t, 3, d.Get("user_id"))
+		assert.Equal(t, "Doe", updated.LastName)
+		assert.Equal(t, "Doe", d.Get("last_name"))
+		assert.True(t, d.Get("is_active").(bool))
+	})
+}
+
+func TestUserGroupIds(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Sync memberships with the configured groups", func(t *testing.T) {
+		var deleted []string
+		svr := mockServer(map[string]interface{}{
+			"GET /api/permissions/membership": client.Memberships{1: {
+				{UserId: 1, GroupId: 1, MembershipId: 10},
+				{UserId: 1, GroupId: 5, MembershipId: 12},
+				{UserId: 1, GroupId: 6, MembershipId: 13},
+			}},
+			"DELETE /api/permissions/membership/13": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				deleted = append(deleted, r.URL.Path)
+				w.WriteHeader(http.StatusNoContent)
+			}),
+			"POST /api/permissions/membership": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`[{"user_id": 1, "membership_id": 14}]`))
+			}),
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{
+			"group_ids": []interface{}{5, 7},
+		})
+
+		groupIds, err := userGroupIds(context.Background(), mockClient(svr), d, 1, true)
+
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []int{5, 7}, groupIds)
+		assert.Equal(t, []string{"/api/permissions/membership/13"}, deleted)
+	})
+}
+
+func TestResourceUserDelete(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Delete user already deleted out-of-band", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{})
+		d.SetId("john.doe@example.com")
+		_ = d.Set("user_id", 1)
+
+		diags := resourceUserDelete(context.Background(), d, mockClient(svr))
+
+		assert.False(t, diags.HasError())
+		assert.Equal(t, "", d.Id())
+	})
+
+	t.Run("Fail on other errors", func(t *testing.T) {
+		svr := mockServer(map[string]interface{}{
+			"DELETE /api/user/1": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			}),
+		})
+		defer svr.Close()
+		d := schema.TestResourceDataRaw(t, resourceUser().Schema, map[string]interface{}{})
+		d.SetId("john.doe@example.com")
+		_ = d.Set("user_id", 1)
+
+		diags := resourceUserDelete(context.Background(), d, mockClient(svr))
+
+		assert.True(t, diags.HasError())
+	})
+}
@@ -0,0 +1,33 @@
+package metabase
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"terraform-provider-metabase/client"
+)
+
+// mockServer serves the given responses keyed by "<METHOD> <path>". A response is either an http.HandlerFunc or a
+// value encoded as JSON. Anything else is answered with a 404, the way Metabase does for unknown objects.
+func mockServer(responses map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, found := responses[r.Method+" "+r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("Not found."))
+			return
+		}
+		if handler, ok := response.(http.HandlerFunc); ok {
+			handler(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+}
+
+func mockClient(svr *httptest.Server) *client.Client {
+	return &client.Client{
+		BaseURL:    svr.URL,
+		HTTPClient: &http.Client{},
+	}
+}